@@ -0,0 +1,167 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+// BeyondAccuracy describes the top-n lists recommended to all the users of an
+// engine as a whole.
+type BeyondAccuracy struct {
+	N int
+
+	// share of the catalogue recommended to at least one user
+	Coverage float64
+	// Gini index of the amount of times every item is recommended, 0 when
+	// they are recommended equally often and close to 1 when a few items
+	// take all the recommendations
+	Gini float64
+	// mean dissimilarity 1 - sim(i, j) of the pairs of items in a list
+	// (intra-list diversity)
+	Diversity float64
+	// mean self-information -log2(p_i) of the recommended items, p_i being
+	// the share of users who rated item i
+	Novelty float64
+	// mean amount of ratings of the recommended items
+	Popularity float64
+}
+
+func (b BeyondAccuracy) String() string {
+	return fmt.Sprintf("@%d: coverage %.4f, Gini %.4f, diversity %.4f, novelty %.4f, popularity %.2f",
+		b.N, b.Coverage, b.Gini, b.Diversity, b.Novelty, b.Popularity)
+}
+
+// EvaluateBeyondAccuracy recommends n items with MakeRecommendationTopN to
+// every user of the engine. similarityMatrix, e.g. the one fitted by
+// ItemBasedStrategy, gives the diversity of the lists, when it is nil the
+// cosine similarity of the items in the preference matrix is used. Items
+// without ratings are counted as rated once for the novelty. The metrics are
+// 0 when nothing is recommended, the diversity when no list has two items.
+func EvaluateBeyondAccuracy[T rec_engine.Key](recEngine *rec_engine.RecEngine[T], n int, similarityMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.Item]) (BeyondAccuracy, error) {
+
+	preferenceMatrix := recEngine.PreferenceMatrix
+	items_n, users_n := preferenceMatrix.RowsN(), preferenceMatrix.ColsN()
+
+	if similarityMatrix == nil {
+
+		var err error
+
+		if similarityMatrix, err = rec_engine.NewItemBasedStrategy().BuildSimilarityMatrix(preferenceMatrix.RowKeys, preferenceMatrix); err != nil {
+			return BeyondAccuracy{}, err
+		}
+	}
+
+	ratings_n := make([]int, items_n)
+
+	for item_n := range items_n {
+
+		_, values := preferenceMatrix.GetSparseRow(item_n)
+
+		for _, value := range values {
+			if value != 0 {
+				ratings_n[item_n]++
+			}
+		}
+	}
+
+	result := BeyondAccuracy{N: n}
+	exposure := make([]float64, items_n)
+
+	recommended, lists := 0, 0
+
+	for _, user := range preferenceMatrix.ColKeys {
+
+		recommendations, err := recEngine.MakeRecommendationTopN(user, n)
+
+		if err != nil {
+			return BeyondAccuracy{}, err
+		}
+
+		for _, r := range recommendations {
+
+			item_n := preferenceMatrix.RowKeyToIndex[r.Item]
+			exposure[item_n]++
+
+			result.Novelty -= math.Log2(float64(max(ratings_n[item_n], 1)) / float64(users_n))
+			result.Popularity += float64(ratings_n[item_n])
+		}
+
+		recommended += len(recommendations)
+
+		if len(recommendations) > 1 {
+			result.Diversity += intraListDiversity(recommendations, similarityMatrix)
+			lists++
+		}
+	}
+
+	covered := 0
+
+	for _, times := range exposure {
+		if times > 0 {
+			covered++
+		}
+	}
+
+	result.Gini = gini(exposure)
+
+	if items_n > 0 {
+		result.Coverage = float64(covered) / float64(items_n)
+	}
+
+	if lists > 0 {
+		result.Diversity /= float64(lists)
+	}
+
+	if recommended > 0 {
+		result.Novelty /= float64(recommended)
+		result.Popularity /= float64(recommended)
+	}
+
+	return result, nil
+}
+
+func intraListDiversity(recommendations []rec_engine.ItemRating, similarityMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.Item]) float64 {
+
+	sum := 0.0
+	pairs := 0
+
+	for i := range recommendations {
+		for k := i + 1; k < len(recommendations); k++ {
+
+			similarity, ok := similarityMatrix.LookupByKey(recommendations[i].Item, recommendations[k].Item)
+
+			if !ok || math.IsNaN(similarity) {
+				similarity = 0
+			}
+
+			sum += 1 - similarity
+			pairs++
+		}
+	}
+
+	return sum / float64(pairs)
+}
+
+// gini returns the Gini index of values, which are sorted in place.
+func gini(values []float64) float64 {
+
+	sort.Float64s(values)
+
+	sum, weighted := 0.0, 0.0
+	n := float64(len(values))
+
+	for i, value := range values {
+		sum += value
+		weighted += (2*float64(i+1) - n - 1) * value
+	}
+
+	if sum == 0 {
+		return 0
+	}
+
+	return weighted / (n * sum)
+}
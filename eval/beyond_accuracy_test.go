@@ -0,0 +1,96 @@
+package eval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+func TestEvaluateBeyondAccuracy(t *testing.T) {
+
+	items := []rec_engine.Item{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}}
+	users := []rec_engine.User{{Id: 1}, {Id: 2}, {Id: 3}}
+
+	preferenceMatrix, err := matrix.NewKeyedMatrix[float64](matrix.NewMatrix([][]float64{
+		{5, 4, 0},
+		{3, 0, 0},
+		{0, 0, 0},
+		{0, 2, 1},
+	}), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the top 2 unrated items are P3, P4 for U1, P2, P3 for U2 and P1, P2
+	// for U3
+	recEngine := rec_engine.NewRecEngine[rec_engine.User](preferenceMatrix, fixedStrategy{items[0]: 5, items[1]: 4, items[2]: 3, items[3]: 2})
+
+	similarityMatrix, err := matrix.NewKeyedMatrix[float64](matrix.NewZeroMatrix[float64](4, 4), items, items)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pair := range []struct {
+		a, b       int
+		similarity float64
+	}{
+		{2, 3, 0.5}, {1, 2, 0.2}, {0, 1, math.NaN()},
+	} {
+		similarityMatrix.Set(pair.a, pair.b, pair.similarity)
+		similarityMatrix.Set(pair.b, pair.a, pair.similarity)
+	}
+
+	result, err := EvaluateBeyondAccuracy(recEngine, 2, similarityMatrix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// P1-P4 are recommended 1, 2, 2 and 1 times and rated by 2, 1, 0 and 2
+	// of the 3 users, P3 counting as rated once
+	want := BeyondAccuracy{
+		N:          2,
+		Coverage:   1,
+		Gini:       1.0 / 6,
+		Diversity:  (0.5 + 0.8 + 1) / 3,
+		Novelty:    (4*math.Log2(3) + 2*math.Log2(1.5)) / 6,
+		Popularity: 1,
+	}
+
+	for _, metric := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"coverage", result.Coverage, want.Coverage},
+		{"Gini", result.Gini, want.Gini},
+		{"diversity", result.Diversity, want.Diversity},
+		{"novelty", result.Novelty, want.Novelty},
+		{"popularity", result.Popularity, want.Popularity},
+	} {
+		if math.Abs(metric.got-metric.want) > 1e-12 {
+			t.Fatalf("%s %v, want %v", metric.name, metric.got, metric.want)
+		}
+	}
+
+	// lists of one item have no diversity, empty lists no metrics at all
+	for _, n := range []int{0, 1} {
+
+		result, err := EvaluateBeyondAccuracy(recEngine, n, nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Diversity != 0 || math.IsNaN(result.Novelty) || math.IsNaN(result.Popularity) {
+			t.Fatalf("top %d: %v", n, result)
+		}
+
+		if n == 0 && (result.Coverage != 0 || result.Novelty != 0 || result.Popularity != 0) {
+			t.Fatalf("nothing recommended: %v, want 0", result)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+// KFold divides the ratings at random into k folds of about the same size,
+// the i-th split holding out the i-th fold.
+func KFold(preferenceMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User], k int, rng *rand.Rand) ([]*Split, error) {
+
+	if k < 2 {
+		return nil, errors.New("k-fold needs at least 2 folds")
+	}
+
+	cells := ratedCells(preferenceMatrix)
+	fold := make([]int, len(cells))
+
+	for n, c := range rng.Perm(len(cells)) {
+		fold[c] = n % k
+	}
+
+	splits := make([]*Split, k)
+
+	for i := range k {
+
+		test := make([]bool, len(cells))
+
+		for c := range cells {
+			test[c] = fold[c] == i
+		}
+
+		split, err := newSplit(preferenceMatrix, cells, test)
+
+		if err != nil {
+			return nil, err
+		}
+		splits[i] = split
+	}
+
+	return splits, nil
+}
+
+// Scorer evaluates a fitted engine on the test ratings, returning the values
+// of the metrics by name.
+type Scorer[T rec_engine.Key] func(recEngine *rec_engine.RecEngine[T], test []Rating) (map[string]float64, error)
+
+// AccuracyScorer scores "RMSE" and "MAE".
+func AccuracyScorer[T rec_engine.Key]() Scorer[T] {
+
+	return func(recEngine *rec_engine.RecEngine[T], test []Rating) (map[string]float64, error) {
+
+		result := Evaluate(recEngine, test)
+		return map[string]float64{"RMSE": result.RMSE, "MAE": result.MAE}, nil
+	}
+}
+
+// RankingScorer scores the macro averages of EvaluateRanking, e.g.
+// "precision@10" for k = 10.
+func RankingScorer[T rec_engine.Key](k int, relevance float64) Scorer[T] {
+
+	return func(recEngine *rec_engine.RecEngine[T], test []Rating) (map[string]float64, error) {
+
+		result, err := EvaluateRanking(recEngine, test, k, relevance)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]float64{
+			fmt.Sprintf("precision@%d", k): result.Macro.Precision,
+			fmt.Sprintf("recall@%d", k):    result.Macro.Recall,
+			fmt.Sprintf("MAP@%d", k):       result.Macro.AP,
+			fmt.Sprintf("MRR@%d", k):       result.Macro.RR,
+			fmt.Sprintf("NDCG@%d", k):      result.Macro.NDCG,
+			fmt.Sprintf("hit rate@%d", k):  result.Macro.Hit,
+			"AUC":                          result.Macro.AUC,
+		}, nil
+	}
+}
+
+// Summary of a metric over the folds, Std being the sample standard
+// deviation.
+type Summary struct {
+	Mean float64
+	Std  float64
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%.4f ± %.4f", s.Mean, s.Std)
+}
+
+type CVResult struct {
+	Params  Params
+	Metrics map[string]Summary
+	// metric values of every fold
+	Folds []map[string]float64
+}
+
+// CrossValidate fits a new strategy on the train part of every split and
+// scores it on the test part, the folds running concurrently. newStrategy is
+// called once per fold because strategies keep their fitted model. Without
+// scorers AccuracyScorer is used.
+func CrossValidate[T rec_engine.Key](folds []*Split, newStrategy func() rec_engine.PredictionStrategy[T], scorers ...Scorer[T]) (CVResult, error) {
+
+	if len(scorers) == 0 {
+		scorers = []Scorer[T]{AccuracyScorer[T]()}
+	}
+
+	result := CVResult{Folds: make([]map[string]float64, len(folds))}
+	errs := make([]error, len(folds))
+
+	var wg sync.WaitGroup
+
+	for i, fold := range folds {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			recEngine := rec_engine.NewRecEngine(fold.Train, newStrategy())
+
+			if err := recEngine.Fit(); err != nil {
+				errs[i] = fmt.Errorf("fold %d: %w", i+1, err)
+				return
+			}
+
+			scores := make(map[string]float64)
+
+			for _, scorer := range scorers {
+
+				metrics, err := scorer(recEngine, fold.Test)
+
+				if err != nil {
+					errs[i] = fmt.Errorf("fold %d: %w", i+1, err)
+					return
+				}
+
+				for name, value := range metrics {
+					scores[name] = value
+				}
+			}
+
+			result.Folds[i] = scores
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return CVResult{}, err
+	}
+
+	result.Metrics = summarize(result.Folds)
+
+	return result, nil
+}
+
+func summarize(folds []map[string]float64) map[string]Summary {
+
+	summaries := make(map[string]Summary)
+
+	if len(folds) == 0 {
+		return summaries
+	}
+
+	for name := range folds[0] {
+
+		sum := 0.0
+
+		for _, scores := range folds {
+			sum += scores[name]
+		}
+
+		mean := sum / float64(len(folds))
+		variance := 0.0
+
+		for _, scores := range folds {
+			variance += (scores[name] - mean) * (scores[name] - mean)
+		}
+
+		if len(folds) > 1 {
+			variance /= float64(len(folds) - 1)
+		}
+
+		summaries[name] = Summary{Mean: mean, Std: math.Sqrt(variance)}
+	}
+
+	return summaries
+}
+
+// metricNames returns the metrics of results in alphabetical order.
+func metricNames(results []CVResult) []string {
+
+	names := []string{}
+
+	if len(results) == 0 {
+		return names
+	}
+
+	for name := range results[0].Metrics {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+func TestKFold(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 15, 12, 0.3)
+
+	folds, err := KFold(preferenceMatrix, 4, rng)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(folds) != 4 {
+		t.Fatalf("%d folds, want 4", len(folds))
+	}
+
+	held_out := make(map[Rating]int)
+	smallest, largest := preferenceMatrix.Nnz(), 0
+
+	for _, fold := range folds {
+
+		assertPartition(t, preferenceMatrix, fold)
+
+		for _, r := range fold.Test {
+			held_out[r]++
+		}
+
+		smallest, largest = min(smallest, len(fold.Test)), max(largest, len(fold.Test))
+	}
+
+	// every rating is held out by exactly one fold
+	if len(held_out) != preferenceMatrix.Nnz() {
+		t.Fatalf("%d ratings held out, want %d", len(held_out), preferenceMatrix.Nnz())
+	}
+
+	for r, n := range held_out {
+		if n != 1 {
+			t.Fatalf("%v held out by %d folds", r, n)
+		}
+	}
+
+	if largest-smallest > 1 {
+		t.Fatalf("fold sizes from %d to %d", smallest, largest)
+	}
+
+	if _, err := KFold(preferenceMatrix, 1, rng); err == nil {
+		t.Fatal("expected an error for 1 fold")
+	}
+}
+
+func TestCrossValidate(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(2))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 5, 4, 1)
+
+	// 20 ratings give folds of 7, 7 and 6
+	folds, err := KFold(preferenceMatrix, 3, rng)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newStrategy := func() rec_engine.PredictionStrategy[rec_engine.User] { return fixedStrategy{} }
+
+	size := func(recEngine *rec_engine.RecEngine[rec_engine.User], test []Rating) (map[string]float64, error) {
+		return map[string]float64{"size": float64(len(test))}, nil
+	}
+
+	result, err := CrossValidate(folds, newStrategy, size)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Folds) != 3 {
+		t.Fatalf("%d fold scores, want 3", len(result.Folds))
+	}
+
+	mean := 20.0 / 3
+	std := math.Sqrt((2*(7-mean)*(7-mean) + (6-mean)*(6-mean)) / 2)
+
+	if got := result.Metrics["size"]; math.Abs(got.Mean-mean) > 1e-12 || math.Abs(got.Std-std) > 1e-12 {
+		t.Fatalf("size %v, want %.4f ± %.4f", got, mean, std)
+	}
+
+	// AccuracyScorer by default
+	result, err = CrossValidate(folds, newStrategy)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := result.Metrics["RMSE"]; !ok {
+		t.Fatalf("metrics %v, want RMSE and MAE", result.Metrics)
+	}
+
+	failing := func(recEngine *rec_engine.RecEngine[rec_engine.User], test []Rating) (map[string]float64, error) {
+		return nil, errors.New("scorer failed")
+	}
+
+	if _, err := CrossValidate(folds, newStrategy, size, failing); err == nil {
+		t.Fatal("expected the error of the scorer")
+	}
+}
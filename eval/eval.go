@@ -0,0 +1,73 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+// Result holds the accuracy of the predictions of the test ratings. RMSE and
+// MAE are computed over the Predicted ones only.
+type Result struct {
+	RMSE float64
+	MAE  float64
+
+	Predicted int
+	// predictions that were NaN, e.g. for a model that could not be fitted
+	NaN int
+	// predictions that failed, e.g. for users or items unknown to the engine
+	Unavailable int
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("RMSE %.4f, MAE %.4f (%d predicted, %d NaN, %d unavailable)", r.RMSE, r.MAE, r.Predicted, r.NaN, r.Unavailable)
+}
+
+// Evaluate predicts every test rating with recEngine.
+func Evaluate[T rec_engine.Key](recEngine *rec_engine.RecEngine[T], test []Rating) Result {
+
+	var result Result
+
+	squared_error := 0.0
+	absolute_error := 0.0
+
+	for _, r := range test {
+
+		prediction, err := recEngine.PredictRating(r.User, r.Item)
+
+		switch {
+		case err != nil:
+			result.Unavailable++
+		case math.IsNaN(prediction):
+			result.NaN++
+		default:
+			squared_error += (prediction - r.Value) * (prediction - r.Value)
+			absolute_error += math.Abs(prediction - r.Value)
+			result.Predicted++
+		}
+	}
+
+	if result.Predicted > 0 {
+		result.RMSE = math.Sqrt(squared_error / float64(result.Predicted))
+		result.MAE = absolute_error / float64(result.Predicted)
+	} else {
+		result.RMSE = math.NaN()
+		result.MAE = math.NaN()
+	}
+
+	return result
+}
+
+// FitAndEvaluate fits a RecEngine with strategy on the train part of split and
+// evaluates it on the test part.
+func FitAndEvaluate[T rec_engine.Key](split *Split, strategy rec_engine.PredictionStrategy[T]) (Result, error) {
+
+	recEngine := rec_engine.NewRecEngine(split.Train, strategy)
+
+	if err := recEngine.Fit(); err != nil {
+		return Result{}, err
+	}
+
+	return Evaluate(recEngine, split.Test), nil
+}
@@ -0,0 +1,65 @@
+package eval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+// fixedStrategy predicts the rating of every item from a map, NaN for the
+// items missing there.
+type fixedStrategy map[rec_engine.Item]float64
+
+func (s fixedStrategy) PredictRating(recEngine *rec_engine.RecEngine[rec_engine.User], target_user rec_engine.User, target_item rec_engine.Item, explanation *rec_engine.Explanation) float64 {
+
+	if rating, ok := s[target_item]; ok {
+		return rating
+	}
+
+	return math.NaN()
+}
+
+func TestEvaluate(t *testing.T) {
+
+	items := []rec_engine.Item{{Id: 1}, {Id: 2}, {Id: 3}}
+	users := []rec_engine.User{{Id: 1}, {Id: 2}}
+
+	preferenceMatrix, err := matrix.NewKeyedMatrix[float64](matrix.NewZeroMatrix[float64](3, 2), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recEngine := rec_engine.NewRecEngine[rec_engine.User](preferenceMatrix, fixedStrategy{items[0]: 3, items[1]: 4})
+
+	result := Evaluate(recEngine, []Rating{
+		// errors 1 and -2
+		{User: users[0], Item: items[0], Value: 4},
+		{User: users[1], Item: items[1], Value: 2},
+		// NaN prediction
+		{User: users[0], Item: items[2], Value: 5},
+		// unknown user
+		{User: rec_engine.User{Id: 9}, Item: items[0], Value: 3},
+	})
+
+	if result.Predicted != 2 || result.NaN != 1 || result.Unavailable != 1 {
+		t.Fatalf("%d predicted, %d NaN, %d unavailable, want 2, 1, 1", result.Predicted, result.NaN, result.Unavailable)
+	}
+
+	if want := math.Sqrt((1 + 4) / 2.0); math.Abs(result.RMSE-want) > 1e-12 {
+		t.Fatalf("RMSE %v, want %v", result.RMSE, want)
+	}
+
+	if result.MAE != 1.5 {
+		t.Fatalf("MAE %v, want 1.5", result.MAE)
+	}
+
+	// nothing predicted
+	result = Evaluate(recEngine, []Rating{{User: users[0], Item: items[2], Value: 5}})
+
+	if !math.IsNaN(result.RMSE) || !math.IsNaN(result.MAE) {
+		t.Fatalf("RMSE %v and MAE %v without predictions, want NaN", result.RMSE, result.MAE)
+	}
+}
@@ -0,0 +1,243 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+// RankingMetrics of a top-k list against the relevant held-out items.
+type RankingMetrics struct {
+	Precision float64
+	Recall    float64
+	// average precision, averaged over users it is MAP
+	AP float64
+	// reciprocal rank of the first relevant item in the top k, 0 when there
+	// is none, averaged over users it is MRR@k
+	RR   float64
+	NDCG float64
+	// 1 when any relevant item is in the list
+	Hit float64
+	// probability that a relevant item is ranked above a non-relevant one,
+	// NaN when the user has no non-relevant candidates
+	AUC float64
+}
+
+func (m RankingMetrics) String() string {
+	return fmt.Sprintf("precision %.4f, recall %.4f, MAP %.4f, MRR %.4f, NDCG %.4f, hit rate %.4f, AUC %.4f",
+		m.Precision, m.Recall, m.AP, m.RR, m.NDCG, m.Hit, m.AUC)
+}
+
+type UserRanking struct {
+	User rec_engine.User
+	// amount of relevant held-out items
+	Relevant int
+	// length of the top-k list, less than k when there are fewer candidates
+	Recommended int
+	RankingMetrics
+}
+
+func (u UserRanking) String() string {
+	return fmt.Sprintf("%v (%d relevant): %v", u.User, u.Relevant, u.RankingMetrics)
+}
+
+type RankingResult struct {
+	K     int
+	Users []UserRanking
+
+	// every user counts the same
+	Macro RankingMetrics
+	// every held-out relevant item counts the same: precision is pooled over
+	// the recommended items and the other metrics are weighted by the amount
+	// of relevant items of each user
+	Micro RankingMetrics
+}
+
+func (r RankingResult) String() string {
+	return fmt.Sprintf("@%d over %d users\nmacro: %v\nmicro: %v", r.K, len(r.Users), r.Macro, r.Micro)
+}
+
+// EvaluateRanking ranks for every user with test ratings all the items the
+// user has not rated in the engine's preference matrix, and compares the
+// top k to the test items rated at least relevance. Users without relevant
+// test items are skipped, an error is returned when that leaves none.
+func EvaluateRanking[T rec_engine.Key](recEngine *rec_engine.RecEngine[T], test []Rating, k int, relevance float64) (RankingResult, error) {
+
+	result := RankingResult{K: k}
+
+	relevant := make(map[rec_engine.User]map[rec_engine.Item]bool)
+	users := []rec_engine.User{}
+
+	for _, r := range test {
+
+		if r.Value < relevance {
+			continue
+		}
+
+		if relevant[r.User] == nil {
+			relevant[r.User] = make(map[rec_engine.Item]bool)
+			users = append(users, r.User)
+		}
+		relevant[r.User][r.Item] = true
+	}
+
+	if len(users) == 0 {
+		return RankingResult{}, errors.New("no test ratings are relevant")
+	}
+
+	for _, user := range users {
+
+		ranking, err := recEngine.MakeRecommendationTopN(user, recEngine.PreferenceMatrix.RowsN())
+
+		if err != nil {
+			return RankingResult{}, err
+		}
+
+		result.Users = append(result.Users, rankUser(user, ranking, relevant[user], k))
+	}
+
+	result.Macro, result.Micro = average(result.Users)
+
+	return result, nil
+}
+
+func rankUser(user rec_engine.User, ranking []rec_engine.ItemRating, relevant map[rec_engine.Item]bool, k int) UserRanking {
+
+	u := UserRanking{User: user, Relevant: len(relevant), Recommended: min(k, len(ranking))}
+
+	hits := 0
+	dcg, idcg := 0.0, 0.0
+	// relevant items ranked so far and pairs ranked correctly for AUC
+	relevant_seen, correct_pairs := 0, 0
+
+	for rank, r := range ranking {
+
+		if !relevant[r.Item] {
+			// every relevant item ranked above is a correct pair
+			correct_pairs += relevant_seen
+			continue
+		}
+
+		relevant_seen++
+
+		if rank < k {
+
+			if u.RR == 0 {
+				u.RR = 1 / float64(rank+1)
+			}
+
+			hits++
+			u.AP += float64(hits) / float64(rank+1)
+			dcg += 1 / math.Log2(float64(rank+2))
+		}
+	}
+
+	for rank := range min(k, len(relevant)) {
+		idcg += 1 / math.Log2(float64(rank+2))
+	}
+
+	if u.Recommended > 0 {
+		u.Precision = float64(hits) / float64(u.Recommended)
+	}
+
+	u.Recall = float64(hits) / float64(len(relevant))
+	u.AP /= float64(min(k, len(relevant)))
+	u.NDCG = dcg / idcg
+
+	if hits > 0 {
+		u.Hit = 1
+	}
+
+	negatives := len(ranking) - relevant_seen
+
+	if relevant_seen > 0 && negatives > 0 {
+		u.AUC = float64(correct_pairs) / float64(relevant_seen*negatives)
+	} else {
+		u.AUC = math.NaN()
+	}
+
+	return u
+}
+
+// average returns the macro and micro averages of the metrics of users,
+// AUC being averaged over the users for which it is defined. users can't be
+// empty, micro precision is 0 when nothing was recommended.
+func average(users []UserRanking) (RankingMetrics, RankingMetrics) {
+
+	var macro, micro RankingMetrics
+
+	macro_auc_n, micro_auc_weight := 0, 0
+	recommended, relevant := 0, 0
+
+	for _, u := range users {
+
+		weight := float64(u.Relevant)
+
+		macro.Precision += u.Precision
+		macro.Recall += u.Recall
+		macro.AP += u.AP
+		macro.RR += u.RR
+		macro.NDCG += u.NDCG
+		macro.Hit += u.Hit
+
+		micro.Precision += u.Precision * float64(u.Recommended)
+		micro.Recall += u.Recall * weight
+		micro.AP += u.AP * weight
+		micro.RR += u.RR * weight
+		micro.NDCG += u.NDCG * weight
+		micro.Hit += u.Hit * weight
+
+		recommended += u.Recommended
+		relevant += u.Relevant
+
+		if !math.IsNaN(u.AUC) {
+			macro.AUC += u.AUC
+			micro.AUC += u.AUC * weight
+			macro_auc_n++
+			micro_auc_weight += u.Relevant
+		}
+	}
+
+	n := float64(len(users))
+
+	macro = RankingMetrics{
+		Precision: macro.Precision / n,
+		Recall:    macro.Recall / n,
+		AP:        macro.AP / n,
+		RR:        macro.RR / n,
+		NDCG:      macro.NDCG / n,
+		Hit:       macro.Hit / n,
+		AUC:       macro.AUC / float64(macro_auc_n),
+	}
+
+	if recommended > 0 {
+		micro.Precision /= float64(recommended)
+	}
+
+	micro = RankingMetrics{
+		Precision: micro.Precision,
+		Recall:    micro.Recall / float64(relevant),
+		AP:        micro.AP / float64(relevant),
+		RR:        micro.RR / float64(relevant),
+		NDCG:      micro.NDCG / float64(relevant),
+		Hit:       micro.Hit / float64(relevant),
+		AUC:       micro.AUC / float64(micro_auc_weight),
+	}
+
+	return macro, micro
+}
+
+// FitAndEvaluateRanking fits a RecEngine with strategy on the train part of
+// split and evaluates its rankings on the test part.
+func FitAndEvaluateRanking[T rec_engine.Key](split *Split, strategy rec_engine.PredictionStrategy[T], k int, relevance float64) (RankingResult, error) {
+
+	recEngine := rec_engine.NewRecEngine(split.Train, strategy)
+
+	if err := recEngine.Fit(); err != nil {
+		return RankingResult{}, err
+	}
+
+	return EvaluateRanking(recEngine, split.Test, k, relevance)
+}
@@ -0,0 +1,59 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+func TestReciprocalRankIsCappedAtK(t *testing.T) {
+
+	ranking := make([]rec_engine.ItemRating, 5)
+
+	for i := range ranking {
+		ranking[i] = rec_engine.ItemRating{Item: rec_engine.Item{Id: i + 1}, Rating: float64(5 - i)}
+	}
+
+	// the only relevant item is 4th
+	relevant := map[rec_engine.Item]bool{{Id: 4}: true}
+
+	if u := rankUser(rec_engine.User{Id: 1}, ranking, relevant, 3); u.RR != 0 || u.Hit != 0 {
+		t.Fatalf("RR@3 = %v and hit rate %v, want 0 for an item ranked 4th", u.RR, u.Hit)
+	}
+
+	if u := rankUser(rec_engine.User{Id: 1}, ranking, relevant, 4); u.RR != 0.25 {
+		t.Fatalf("RR@4 = %v, want 0.25", u.RR)
+	}
+}
+
+func TestEvaluateRankingWithoutRelevantRatings(t *testing.T) {
+
+	items := []rec_engine.Item{{Id: 1}, {Id: 2}}
+	users := []rec_engine.User{{Id: 1}}
+
+	preferenceMatrix, err := matrix.NewKeyedMatrix[float64](matrix.NewMatrix([][]float64{{4}, {5}}), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recEngine := rec_engine.NewRecEngine[rec_engine.User](preferenceMatrix, rec_engine.NewBaselineStrategy[rec_engine.User]())
+
+	for _, test := range [][]Rating{nil, {{User: users[0], Item: items[0], Value: 2}}} {
+		if _, err := EvaluateRanking(recEngine, test, 5, 4); err == nil {
+			t.Fatalf("%v: expected an error without relevant test ratings", test)
+		}
+	}
+
+	// the user rated every item, so nothing is recommended
+	result, err := EvaluateRanking(recEngine, []Rating{{User: users[0], Item: items[0], Value: 4}}, 5, 4)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Micro.Precision != 0 || result.Macro.Precision != 0 || result.Micro.Recall != 0 {
+		t.Fatalf("metrics %v and %v, want 0 without recommendations", result.Macro, result.Micro)
+	}
+}
@@ -0,0 +1,158 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+// Params is a configuration of a strategy by parameter name. Values which do
+// not print well, e.g. similarity functions, are better given by a name the
+// factory maps to the value.
+type Params map[string]any
+
+func (p Params) String() string {
+
+	names := make([]string, 0, len(p))
+
+	for name := range p {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s=%v", name, p[name])
+	}
+
+	return strings.Join(names, " ")
+}
+
+// Grid holds the values tried for every parameter.
+type Grid map[string][]any
+
+// Params returns every combination of the values of the parameters.
+func (g Grid) Params() []Params {
+
+	names := make([]string, 0, len(g))
+
+	for name := range g {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	combinations := []Params{{}}
+
+	for _, name := range names {
+
+		next := make([]Params, 0, len(combinations)*len(g[name]))
+
+		for _, params := range combinations {
+			for _, value := range g[name] {
+
+				combination := make(Params, len(params)+1)
+
+				for k, v := range params {
+					combination[k] = v
+				}
+				combination[name] = value
+
+				next = append(next, combination)
+			}
+		}
+
+		combinations = next
+	}
+
+	return combinations
+}
+
+// Sample returns n combinations of the grid chosen at random, for a random
+// search, or all of them in random order when there are fewer.
+func (g Grid) Sample(n int, rng *rand.Rand) []Params {
+
+	combinations := g.Params()
+
+	rng.Shuffle(len(combinations), func(i, j int) {
+		combinations[i], combinations[j] = combinations[j], combinations[i]
+	})
+
+	return combinations[:min(n, len(combinations))]
+}
+
+// Search cross-validates the strategy built by factory for every params on
+// the same folds and returns the results ranked by the mean of metric, the
+// lowest first when lower_is_better, e.g. for "RMSE".
+func Search[T rec_engine.Key](folds []*Split,
+	params []Params,
+	factory func(Params) rec_engine.PredictionStrategy[T],
+	metric string,
+	lower_is_better bool,
+	scorers ...Scorer[T]) ([]CVResult, error) {
+
+	results := make([]CVResult, 0, len(params))
+
+	for _, p := range params {
+
+		result, err := CrossValidate(folds, func() rec_engine.PredictionStrategy[T] { return factory(p) }, scorers...)
+
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", p, err)
+		}
+
+		if _, ok := result.Metrics[metric]; !ok {
+			return nil, fmt.Errorf("unknown metric %q", metric)
+		}
+
+		result.Params = p
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+
+		a, b := results[i].Metrics[metric].Mean, results[j].Metrics[metric].Mean
+
+		// NaN scores go last
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return !math.IsNaN(a)
+		}
+
+		if lower_is_better {
+			return a < b
+		}
+		return a > b
+	})
+
+	return results, nil
+}
+
+// Table formats results in their order with the mean ± std of every metric.
+func Table(results []CVResult) string {
+
+	var sb strings.Builder
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	names := metricNames(results)
+
+	fmt.Fprintf(w, "#\tparams\t%s\n", strings.Join(names, "\t"))
+
+	for i, result := range results {
+
+		fmt.Fprintf(w, "%d\t%v", i+1, result.Params)
+
+		for _, name := range names {
+			fmt.Fprintf(w, "\t%v", result.Metrics[name])
+		}
+		fmt.Fprintln(w)
+	}
+
+	w.Flush()
+
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+func TestGridParams(t *testing.T) {
+
+	grid := Grid{"b": {"x", "y", "z"}, "a": {1, 2}}
+	params := grid.Params()
+
+	if len(params) != 6 {
+		t.Fatalf("%d combinations, want 6", len(params))
+	}
+
+	// the parameters are combined in the order of their names
+	if params[0].String() != "a=1 b=x" || params[1].String() != "a=1 b=y" || params[5].String() != "a=2 b=z" {
+		t.Fatalf("combinations %v", params)
+	}
+
+	seen := make(map[string]bool)
+
+	for _, p := range params {
+		seen[p.String()] = true
+	}
+
+	if len(seen) != 6 {
+		t.Fatalf("combinations %v aren't distinct", params)
+	}
+
+	if sample := grid.Sample(4, rand.New(rand.NewSource(1))); len(sample) != 4 {
+		t.Fatalf("%d sampled, want 4", len(sample))
+	}
+
+	if sample := grid.Sample(10, rand.New(rand.NewSource(1))); len(sample) != 6 {
+		t.Fatalf("%d sampled, want all 6", len(sample))
+	}
+
+	if params := (Grid{}).Params(); len(params) != 1 || len(params[0]) != 0 {
+		t.Fatalf("empty grid: %v, want one empty combination", params)
+	}
+
+	if params := (Grid{"a": {1}, "b": {}}).Params(); len(params) != 0 {
+		t.Fatalf("a parameter without values: %v, want none", params)
+	}
+}
+
+func TestSearchOrder(t *testing.T) {
+
+	items := []rec_engine.Item{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}}
+	users := []rec_engine.User{{Id: 1}, {Id: 2}, {Id: 3}}
+
+	preferenceMatrix, err := matrix.NewKeyedMatrix[float64](matrix.NewMatrix([][]float64{
+		{3, 3, 3},
+		{3, 3, 3},
+		{3, 3, 3},
+		{3, 3, 3},
+	}), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folds, err := KFold(preferenceMatrix, 3, rand.New(rand.NewSource(1)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a constant rating is off by |rating - 3|, without one every prediction
+	// is NaN and so is RMSE
+	factory := func(p Params) rec_engine.PredictionStrategy[rec_engine.User] {
+
+		strategy := fixedStrategy{}
+
+		if rating, ok := p["rating"].(float64); ok {
+			for _, item := range items {
+				strategy[item] = rating
+			}
+		}
+		return strategy
+	}
+
+	params := []Params{{"rating": 5.0}, {"rating": 3.5}, {}, {"rating": 2.0}}
+
+	for _, test := range []struct {
+		lower_is_better bool
+		want            []string
+	}{
+		{true, []string{"rating=3.5", "rating=2", "rating=5", ""}},
+		{false, []string{"rating=5", "rating=2", "rating=3.5", ""}},
+	} {
+
+		results, err := Search(folds, params, factory, "RMSE", test.lower_is_better)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, result := range results {
+			if result.Params.String() != test.want[i] {
+				t.Fatalf("lower is better %v: result %d is %q, want %q", test.lower_is_better, i+1, result.Params, test.want[i])
+			}
+		}
+
+		if last := results[len(results)-1].Metrics["RMSE"].Mean; !math.IsNaN(last) {
+			t.Fatalf("last RMSE %v, want NaN", last)
+		}
+	}
+
+	if _, err := Search(folds, params, factory, "AUC", true); err == nil {
+		t.Fatal("expected an error for a metric the scorers don't compute")
+	}
+}
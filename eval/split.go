@@ -0,0 +1,178 @@
+package eval
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+// Rating is a held-out cell of the preference matrix.
+type Rating struct {
+	User  rec_engine.User
+	Item  rec_engine.Item
+	Value float64
+}
+
+// Split is a preference matrix divided into a train matrix, which keeps all
+// the users and items of the original one, and the held-out test ratings.
+type Split struct {
+	Train *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User]
+	Test  []Rating
+}
+
+// cell is a rating of the preference matrix by row and column index.
+type cell struct {
+	item_n int
+	user_n int
+	value  float64
+}
+
+func ratedCells(preferenceMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User]) []cell {
+
+	cells := make([]cell, 0, preferenceMatrix.Nnz())
+
+	for item_n := range preferenceMatrix.RowsN() {
+
+		indices, values := preferenceMatrix.GetSparseRow(item_n)
+
+		for k, user_n := range indices {
+
+			if values[k] != 0 {
+				cells = append(cells, cell{item_n: item_n, user_n: user_n, value: values[k]})
+			}
+		}
+	}
+
+	return cells
+}
+
+// newSplit puts the cells for which test is true into Test and the others
+// into Train.
+func newSplit(preferenceMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User], cells []cell, test []bool) (*Split, error) {
+
+	train := matrix.NewCoordinateList[float64](preferenceMatrix.RowsN(), preferenceMatrix.ColsN())
+	split := &Split{}
+
+	for k, c := range cells {
+
+		if test[k] {
+			split.Test = append(split.Test, Rating{
+				User:  preferenceMatrix.ColKeys[c.user_n],
+				Item:  preferenceMatrix.RowKeys[c.item_n],
+				Value: c.value,
+			})
+		} else {
+			train.Append(c.item_n, c.user_n, c.value)
+		}
+	}
+
+	var err error
+
+	split.Train, err = matrix.NewKeyedMatrix[float64](matrix.NewDualSparse(train.ToCSR()), preferenceMatrix.RowKeys, preferenceMatrix.ColKeys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return split, nil
+}
+
+// RandomSplit holds out test_fraction of all the ratings chosen at random.
+func RandomSplit(preferenceMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User], test_fraction float64, rng *rand.Rand) (*Split, error) {
+
+	if test_fraction < 0 || test_fraction > 1 {
+		return nil, errors.New("test fraction is out of range")
+	}
+
+	cells := ratedCells(preferenceMatrix)
+	test := make([]bool, len(cells))
+
+	for _, k := range rng.Perm(len(cells))[:int(test_fraction*float64(len(cells)))] {
+		test[k] = true
+	}
+
+	return newSplit(preferenceMatrix, cells, test)
+}
+
+// PerUserSplit holds out holdout random ratings of every user who has more
+// than holdout of them, so that every user keeps ratings to train on.
+func PerUserSplit(preferenceMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User], holdout int, rng *rand.Rand) (*Split, error) {
+
+	if holdout < 0 {
+		return nil, errors.New("holdout is negative")
+	}
+
+	cells := ratedCells(preferenceMatrix)
+	test := make([]bool, len(cells))
+	user_cells := make([][]int, preferenceMatrix.ColsN())
+
+	for k, c := range cells {
+		user_cells[c.user_n] = append(user_cells[c.user_n], k)
+	}
+
+	for _, rated := range user_cells {
+
+		if len(rated) <= holdout {
+			continue
+		}
+
+		rng.Shuffle(len(rated), func(i, j int) {
+			rated[i], rated[j] = rated[j], rated[i]
+		})
+
+		for _, k := range rated[:holdout] {
+			test[k] = true
+		}
+	}
+
+	return newSplit(preferenceMatrix, cells, test)
+}
+
+// TimestampSplit holds out the test_fraction of the ratings made last, as
+// recorded in timestamps, e.g. loader.Ratings.Timestamps.
+func TimestampSplit(preferenceMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User],
+	timestamps *matrix.KeyedMatrix[int64, rec_engine.Item, rec_engine.User],
+	test_fraction float64) (*Split, error) {
+
+	if timestamps == nil {
+		return nil, errors.New("no timestamps")
+	}
+
+	if test_fraction < 0 || test_fraction > 1 {
+		return nil, errors.New("test fraction is out of range")
+	}
+
+	cells := ratedCells(preferenceMatrix)
+	times := make([]int64, len(cells))
+
+	for k, c := range cells {
+
+		time, ok := timestamps.LookupByKey(preferenceMatrix.RowKeys[c.item_n], preferenceMatrix.ColKeys[c.user_n])
+
+		if !ok {
+			return nil, errors.New("timestamps don't have the keys of the preference matrix")
+		}
+		times[k] = time
+	}
+
+	order := make([]int, len(cells))
+
+	for k := range order {
+		order[k] = k
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return times[order[i]] < times[order[j]]
+	})
+
+	test := make([]bool, len(cells))
+
+	for _, k := range order[len(cells)-int(test_fraction*float64(len(cells))):] {
+		test[k] = true
+	}
+
+	return newSplit(preferenceMatrix, cells, test)
+}
@@ -0,0 +1,213 @@
+package eval
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+func randomPreferenceMatrix(t *testing.T, rng *rand.Rand, items_n int, users_n int, density float64) *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User] {
+
+	t.Helper()
+
+	items := make([]rec_engine.Item, items_n)
+	users := make([]rec_engine.User, users_n)
+
+	for i := range items {
+		items[i] = rec_engine.Item{Id: i + 1}
+	}
+
+	for i := range users {
+		users[i] = rec_engine.User{Id: i + 1}
+	}
+
+	coo := matrix.NewCoordinateList[float64](items_n, users_n)
+
+	for item_n := range items_n {
+		for user_n := range users_n {
+
+			if rng.Float64() < density {
+				coo.Append(item_n, user_n, float64(1+rng.Intn(5)))
+			}
+		}
+	}
+
+	preferenceMatrix, err := matrix.NewKeyedMatrix[float64](matrix.NewDualSparse(coo.ToCSR()), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return preferenceMatrix
+}
+
+// assertPartition checks that every rating of preferenceMatrix is either in
+// the train matrix or in the test ratings, never in both.
+func assertPartition(t *testing.T, preferenceMatrix *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User], split *Split) {
+
+	t.Helper()
+
+	if split.Train.RowsN() != preferenceMatrix.RowsN() || split.Train.ColsN() != preferenceMatrix.ColsN() {
+		t.Fatalf("train matrix is %dx%d, want %dx%d", split.Train.RowsN(), split.Train.ColsN(), preferenceMatrix.RowsN(), preferenceMatrix.ColsN())
+	}
+
+	if split.Train.Nnz()+len(split.Test) != preferenceMatrix.Nnz() {
+		t.Fatalf("%d train and %d test ratings out of %d", split.Train.Nnz(), len(split.Test), preferenceMatrix.Nnz())
+	}
+
+	for _, r := range split.Test {
+
+		if train, _ := split.Train.LookupByKey(r.Item, r.User); train != 0 {
+			t.Fatalf("test rating of %v by %v is also in the train matrix", r.Item, r.User)
+		}
+
+		if rating, _ := preferenceMatrix.LookupByKey(r.Item, r.User); rating != r.Value {
+			t.Fatalf("test rating of %v by %v is %v, want %v", r.Item, r.User, r.Value, rating)
+		}
+	}
+
+	for item_n := range split.Train.RowsN() {
+
+		indices, values := split.Train.GetSparseRow(item_n)
+
+		for k, user_n := range indices {
+			if values[k] != preferenceMatrix.Get(item_n, user_n) {
+				t.Fatalf("train (%d, %d) = %v, want %v", item_n, user_n, values[k], preferenceMatrix.Get(item_n, user_n))
+			}
+		}
+	}
+}
+
+func TestRandomSplit(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 20, 15, 0.3)
+
+	for _, fraction := range []float64{0, 0.2, 1} {
+
+		split, err := RandomSplit(preferenceMatrix, fraction, rng)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertPartition(t, preferenceMatrix, split)
+
+		if want := int(fraction * float64(preferenceMatrix.Nnz())); len(split.Test) != want {
+			t.Fatalf("fraction %v: %d test ratings, want %d", fraction, len(split.Test), want)
+		}
+	}
+
+	for _, fraction := range []float64{-0.1, 1.1} {
+		if _, err := RandomSplit(preferenceMatrix, fraction, rng); err == nil {
+			t.Fatalf("expected an error for fraction %v", fraction)
+		}
+	}
+}
+
+func TestPerUserSplit(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(2))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 10, 30, 0.3)
+
+	split, err := PerUserSplit(preferenceMatrix, 2, rng)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertPartition(t, preferenceMatrix, split)
+
+	held_out := make(map[rec_engine.User]int)
+
+	for _, r := range split.Test {
+		held_out[r.User]++
+	}
+
+	for user_n, user := range preferenceMatrix.ColKeys {
+
+		rated, _ := preferenceMatrix.GetSparseCol(user_n)
+		kept, _ := split.Train.GetSparseCol(user_n)
+
+		want := 0
+
+		if len(rated) > 2 {
+			want = 2
+		}
+
+		if held_out[user] != want || len(kept) != len(rated)-want {
+			t.Fatalf("%v with %d ratings: %d held out and %d kept, want %d held out", user, len(rated), held_out[user], len(kept), want)
+		}
+	}
+
+	if _, err := PerUserSplit(preferenceMatrix, -1, rng); err == nil {
+		t.Fatal("expected an error for a negative holdout")
+	}
+}
+
+func TestTimestampSplit(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(3))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 10, 10, 0.5)
+
+	timestamps, err := matrix.NewKeyedMatrix[int64](matrix.NewZeroMatrix[int64](10, 10), preferenceMatrix.RowKeys, preferenceMatrix.ColKeys)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// distinct times in random order
+	for k, n := range rng.Perm(100) {
+		timestamps.Set(n/10, n%10, int64(1000+k))
+	}
+
+	split, err := TimestampSplit(preferenceMatrix, timestamps, 0.25)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertPartition(t, preferenceMatrix, split)
+
+	if want := int(0.25 * float64(preferenceMatrix.Nnz())); len(split.Test) != want {
+		t.Fatalf("%d test ratings, want %d", len(split.Test), want)
+	}
+
+	first_test := int64(-1)
+
+	for _, r := range split.Test {
+
+		time, _ := timestamps.LookupByKey(r.Item, r.User)
+
+		if first_test < 0 || time < first_test {
+			first_test = time
+		}
+	}
+
+	for item_n := range split.Train.RowsN() {
+
+		indices, _ := split.Train.GetSparseRow(item_n)
+
+		for _, user_n := range indices {
+			if timestamps.Get(item_n, user_n) >= first_test {
+				t.Fatalf("train rating (%d, %d) at %d is not older than the test ones from %d", item_n, user_n, timestamps.Get(item_n, user_n), first_test)
+			}
+		}
+	}
+
+	if _, err := TimestampSplit(preferenceMatrix, nil, 0.25); err == nil {
+		t.Fatal("expected an error without timestamps")
+	}
+
+	other, err := matrix.NewKeyedMatrix[int64](matrix.NewZeroMatrix[int64](1, 1), []rec_engine.Item{{Id: 99}}, []rec_engine.User{{Id: 99}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := TimestampSplit(preferenceMatrix, other, 0.25); err == nil {
+		t.Fatal("expected an error for timestamps of other keys")
+	}
+}
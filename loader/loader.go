@@ -0,0 +1,370 @@
+package loader
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+type Format int
+
+const (
+	// userId,itemId,rating[,timestamp] with an optional header line
+	CSV Format = iota
+	// same columns as CSV separated by tabs
+	TSV
+	// MovieLens 100K u.data: user, item, rating and timestamp separated by tabs
+	MovieLens100K
+	// MovieLens 1M/10M ratings.dat: UserID::MovieID::Rating::Timestamp
+	MovieLens1M
+)
+
+func (f Format) separator() string {
+
+	switch f {
+	case TSV, MovieLens100K:
+		return "\t"
+	case MovieLens1M:
+		return "::"
+	default:
+		return ","
+	}
+}
+
+// DetectFormat guesses the format of a ratings file from its name.
+func DetectFormat(path string) Format {
+
+	name := strings.ToLower(filepath.Base(path))
+
+	base, _ := filepath.Match("u*.base", name)
+	test, _ := filepath.Match("u*.test", name)
+
+	switch {
+	case name == "u.data" || base || test:
+		return MovieLens100K
+	case strings.HasSuffix(name, ".dat"):
+		return MovieLens1M
+	case strings.HasSuffix(name, ".tsv"):
+		return TSV
+	default:
+		return CSV
+	}
+}
+
+type Diagnostic struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("line %d: %v: %q", d.Line, d.Err, d.Text)
+}
+
+var (
+	ErrMalformedLine   = errors.New("malformed line")
+	ErrZeroRating      = errors.New("zero rating cannot be distinguished from a missing one")
+	ErrDuplicateRating = errors.New("duplicate rating, the later line is kept")
+)
+
+type Ratings struct {
+	Preferences *matrix.KeyedMatrix[float64, rec_engine.Item, rec_engine.User]
+	// nil when the file has no timestamp column
+	Timestamps *matrix.KeyedMatrix[int64, rec_engine.Item, rec_engine.User]
+
+	Users []rec_engine.User
+	Items []rec_engine.Item
+
+	Diagnostics []Diagnostic
+}
+
+type rating struct {
+	user      int
+	item      int
+	value     float64
+	timestamp int64
+}
+
+func parseRating(line string, separator string) (rating, bool, error) {
+
+	fields := strings.Split(line, separator)
+
+	if len(fields) < 3 || len(fields) > 4 {
+		return rating{}, false, ErrMalformedLine
+	}
+
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	r := rating{}
+	var err error
+
+	if r.user, err = strconv.Atoi(fields[0]); err != nil {
+		return r, false, ErrMalformedLine
+	}
+
+	if r.item, err = strconv.Atoi(fields[1]); err != nil {
+		return r, false, ErrMalformedLine
+	}
+
+	if r.value, err = strconv.ParseFloat(fields[2], 64); err != nil {
+		return r, false, ErrMalformedLine
+	}
+
+	if len(fields) == 4 {
+
+		if r.timestamp, err = strconv.ParseInt(fields[3], 10, 64); err != nil {
+			return r, false, ErrMalformedLine
+		}
+	}
+
+	return r, len(fields) == 4, nil
+}
+
+// LoadRatings reads ratings in the given format and builds a sparse
+// preference matrix with items as rows and users as columns. Items found in
+// titles get their Name set. Malformed lines are skipped and reported in
+// Diagnostics; only read errors are returned.
+func LoadRatings(r io.Reader, format Format, titles map[int]string) (*Ratings, error) {
+
+	scanner := bufio.NewScanner(r)
+	separator := format.separator()
+
+	ratings := []rating{}
+	seen := make(map[[2]int]int)
+	has_timestamps := false
+
+	result := &Ratings{}
+	line_n := 0
+	first := true
+
+	for scanner.Scan() {
+
+		line_n++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		parsed, has_timestamp, err := parseRating(line, separator)
+		is_first := first
+		first = false
+
+		if err != nil {
+
+			// header line of CSV/TSV files, e.g. "userId,movieId,rating,timestamp"
+			if is_first && (format == CSV || format == TSV) {
+				continue
+			}
+
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{Line: line_n, Text: line, Err: err})
+			continue
+		}
+
+		if parsed.value == 0 {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{Line: line_n, Text: line, Err: ErrZeroRating})
+			continue
+		}
+
+		has_timestamps = has_timestamps || has_timestamp
+		key := [2]int{parsed.user, parsed.item}
+
+		if i, ok := seen[key]; ok {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{Line: line_n, Text: line, Err: ErrDuplicateRating})
+			ratings[i] = parsed
+			continue
+		}
+
+		seen[key] = len(ratings)
+		ratings = append(ratings, parsed)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	user_ids := []int{}
+	item_ids := []int{}
+	user_index := make(map[int]int)
+	item_index := make(map[int]int)
+
+	for _, r := range ratings {
+
+		if _, ok := user_index[r.user]; !ok {
+			user_index[r.user] = -1
+			user_ids = append(user_ids, r.user)
+		}
+
+		if _, ok := item_index[r.item]; !ok {
+			item_index[r.item] = -1
+			item_ids = append(item_ids, r.item)
+		}
+	}
+
+	slices.Sort(user_ids)
+	slices.Sort(item_ids)
+
+	for i, id := range user_ids {
+		user_index[id] = i
+		result.Users = append(result.Users, rec_engine.User{Id: id})
+	}
+
+	for i, id := range item_ids {
+		item_index[id] = i
+		result.Items = append(result.Items, rec_engine.Item{Id: id, Name: titles[id]})
+	}
+
+	values := matrix.NewCoordinateList[float64](len(item_ids), len(user_ids))
+	timestamps := matrix.NewCoordinateList[int64](len(item_ids), len(user_ids))
+
+	for _, r := range ratings {
+		values.Append(item_index[r.item], user_index[r.user], r.value)
+		timestamps.Append(item_index[r.item], user_index[r.user], r.timestamp)
+	}
+
+	var err error
+
+	result.Preferences, err = matrix.NewKeyedMatrix[float64](matrix.NewDualSparse(values.ToCSR()), result.Items, result.Users)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if has_timestamps {
+
+		csr := timestamps.ToCSR()
+		result.Timestamps, err = matrix.NewKeyedMatrix[int64](&csr, result.Items, result.Users)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// LoadRatingsFile opens path and loads it with the format guessed by
+// DetectFormat.
+func LoadRatingsFile(path string, titles map[int]string) (*Ratings, error) {
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadRatings(file, DetectFormat(path), titles)
+}
+
+// LoadTitles reads item titles from MovieLens u.item (MovieLens100K),
+// movies.dat (MovieLens1M) or movies.csv (CSV) files.
+func LoadTitles(r io.Reader, format Format) (map[int]string, []Diagnostic, error) {
+
+	titles := make(map[int]string)
+	diagnostics := []Diagnostic{}
+
+	add := func(line_n int, text string, fields []string) {
+
+		if len(fields) < 2 {
+			diagnostics = append(diagnostics, Diagnostic{Line: line_n, Text: text, Err: ErrMalformedLine})
+			return
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+
+		if err != nil {
+
+			if line_n > 1 {
+				diagnostics = append(diagnostics, Diagnostic{Line: line_n, Text: text, Err: ErrMalformedLine})
+			}
+			return
+		}
+
+		titles[id] = strings.TrimSpace(fields[1])
+	}
+
+	if format == CSV {
+
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+
+		for {
+			record, err := reader.Read()
+
+			if err == io.EOF {
+				break
+			}
+
+			var parse_err *csv.ParseError
+
+			if errors.As(err, &parse_err) {
+				diagnostics = append(diagnostics, Diagnostic{Line: parse_err.Line, Err: ErrMalformedLine})
+				continue
+			}
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			line_n, _ := reader.FieldPos(0)
+			add(line_n, strings.Join(record, ","), record)
+		}
+
+		return titles, diagnostics, nil
+	}
+
+	separator := "|"
+
+	switch format {
+	case MovieLens1M:
+		separator = "::"
+	case TSV:
+		separator = "\t"
+	}
+
+	scanner := bufio.NewScanner(r)
+	line_n := 0
+
+	for scanner.Scan() {
+
+		line_n++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		add(line_n, line, strings.Split(line, separator))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return titles, diagnostics, nil
+}
+
+func LoadTitlesFile(path string, format Format) (map[int]string, []Diagnostic, error) {
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return LoadTitles(file, format)
+}
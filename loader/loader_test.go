@@ -0,0 +1,250 @@
+package loader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PetrDoroshev/RS/rec_engine"
+)
+
+func load(t *testing.T, input string, format Format, titles map[int]string) *Ratings {
+
+	t.Helper()
+
+	ratings, err := LoadRatings(strings.NewReader(input), format, titles)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ratings
+}
+
+// assertRating checks the rating of item by user, 0 meaning unrated.
+func assertRating(t *testing.T, ratings *Ratings, user int, item int, want float64) {
+
+	t.Helper()
+
+	// items are keyed with their titles
+	key := rec_engine.Item{Id: item}
+
+	for _, it := range ratings.Items {
+		if it.Id == item {
+			key = it
+		}
+	}
+
+	item_n, err := ratings.Preferences.RowIndex(key)
+
+	if err != nil {
+		t.Fatalf("item %d: %v", item, err)
+	}
+
+	user_n, err := ratings.Preferences.ColIndex(rec_engine.User{Id: user})
+
+	if err != nil {
+		t.Fatalf("user %d: %v", user, err)
+	}
+
+	if got := ratings.Preferences.Get(item_n, user_n); got != want {
+		t.Fatalf("rating of item %d by user %d = %v, want %v", item, user, got, want)
+	}
+}
+
+func assertDiagnostics(t *testing.T, ratings *Ratings, want map[int]error) {
+
+	t.Helper()
+
+	if len(ratings.Diagnostics) != len(want) {
+		t.Fatalf("diagnostics %v, want %d", ratings.Diagnostics, len(want))
+	}
+
+	for _, d := range ratings.Diagnostics {
+		if !errors.Is(d.Err, want[d.Line]) {
+			t.Fatalf("line %d: %v, want %v", d.Line, d.Err, want[d.Line])
+		}
+	}
+}
+
+func TestLoadRatingsFormats(t *testing.T) {
+
+	inputs := map[Format]string{
+		CSV:           "userId,movieId,rating,timestamp\n1,10,4,100\n2,10,3.5,200\n1,20,5,300\n",
+		TSV:           "1\t10\t4\t100\n2\t10\t3.5\t200\n1\t20\t5\t300\n",
+		MovieLens100K: "1\t10\t4\t100\n2\t10\t3.5\t200\n1\t20\t5\t300\n",
+		MovieLens1M:   "1::10::4::100\n2::10::3.5::200\n1::20::5::300\n",
+	}
+
+	for format, input := range inputs {
+
+		ratings := load(t, input, format, nil)
+
+		if len(ratings.Users) != 2 || len(ratings.Items) != 2 || len(ratings.Diagnostics) != 0 {
+			t.Fatalf("format %d: users %v, items %v, diagnostics %v", format, ratings.Users, ratings.Items, ratings.Diagnostics)
+		}
+
+		// users and items sorted by id
+		if ratings.Users[0].Id != 1 || ratings.Items[1].Id != 20 {
+			t.Fatalf("format %d: users %v, items %v", format, ratings.Users, ratings.Items)
+		}
+
+		assertRating(t, ratings, 1, 10, 4)
+		assertRating(t, ratings, 2, 10, 3.5)
+		assertRating(t, ratings, 1, 20, 5)
+		assertRating(t, ratings, 2, 20, 0)
+
+		if ratings.Preferences.Nnz() != 3 {
+			t.Fatalf("format %d: %d ratings, want 3", format, ratings.Preferences.Nnz())
+		}
+	}
+}
+
+func TestLoadRatingsHeader(t *testing.T) {
+
+	// only the first line of CSV/TSV may be a header
+	ratings := load(t, "user\titem\trating\n1\t10\t4\nuser\titem\trating\n", TSV, nil)
+	assertDiagnostics(t, ratings, map[int]error{3: ErrMalformedLine})
+	assertRating(t, ratings, 1, 10, 4)
+
+	ratings = load(t, "UserID::MovieID::Rating\n1::10::4\n", MovieLens1M, nil)
+	assertDiagnostics(t, ratings, map[int]error{1: ErrMalformedLine})
+}
+
+func TestLoadRatingsDiagnostics(t *testing.T) {
+
+	input := `1,10,4
+1,x,3
+2,10
+
+2,20,0
+1,10,2
+3,20,1,2,3
+`
+
+	ratings := load(t, input, CSV, nil)
+
+	assertDiagnostics(t, ratings, map[int]error{
+		2: ErrMalformedLine,
+		3: ErrMalformedLine,
+		5: ErrZeroRating,
+		6: ErrDuplicateRating,
+		7: ErrMalformedLine,
+	})
+
+	// the later line wins
+	assertRating(t, ratings, 1, 10, 2)
+
+	if len(ratings.Users) != 1 || len(ratings.Items) != 1 {
+		t.Fatalf("users %v, items %v, want only the valid ratings", ratings.Users, ratings.Items)
+	}
+
+	if d := ratings.Diagnostics[0].String(); !strings.Contains(d, "line 2") || !strings.Contains(d, `"1,x,3"`) {
+		t.Fatalf("diagnostic %q", d)
+	}
+}
+
+func TestLoadRatingsTimestamps(t *testing.T) {
+
+	ratings := load(t, "1,10,4,100\n2,10,3,200\n1,10,5,300\n", CSV, nil)
+
+	if ratings.Timestamps == nil {
+		t.Fatal("timestamps weren't loaded")
+	}
+
+	item_n, _ := ratings.Preferences.RowIndex(rec_engine.Item{Id: 10})
+
+	for user, want := range map[int]int64{1: 300, 2: 200} {
+
+		user_n, _ := ratings.Preferences.ColIndex(rec_engine.User{Id: user})
+
+		if got := ratings.Timestamps.Get(item_n, user_n); got != want {
+			t.Fatalf("timestamp of user %d = %d, want %d", user, got, want)
+		}
+	}
+
+	if ratings := load(t, "1,10,4\n", CSV, nil); ratings.Timestamps != nil {
+		t.Fatal("timestamps without a timestamp column")
+	}
+}
+
+func TestLoadTitles(t *testing.T) {
+
+	inputs := []struct {
+		format Format
+		input  string
+	}{
+		{MovieLens100K, "10|GoldenEye (1995)|01-Jan-1995||http://example.com|0|1\n20|Four Rooms (1995)|01-Jan-1995||x|0|0\n"},
+		{MovieLens1M, "10::GoldenEye (1995)::Action\n20::Four Rooms (1995)::Thriller\n"},
+		{CSV, "movieId,title,genres\n10,GoldenEye (1995),Action\n20,\"Four Rooms (1995)\",Thriller\n"},
+	}
+
+	for _, test := range inputs {
+
+		titles, diagnostics, err := LoadTitles(strings.NewReader(test.input), test.format)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(diagnostics) != 0 || titles[10] != "GoldenEye (1995)" || titles[20] != "Four Rooms (1995)" {
+			t.Fatalf("format %d: titles %v, diagnostics %v", test.format, titles, diagnostics)
+		}
+
+		ratings := load(t, "1,10,4\n1,30,2\n", CSV, titles)
+
+		if ratings.Items[0].Name != "GoldenEye (1995)" || ratings.Items[1].Name != "" {
+			t.Fatalf("format %d: items %#v", test.format, ratings.Items)
+		}
+
+		assertRating(t, ratings, 1, 10, 4)
+	}
+
+	// a title with a comma is quoted in movies.csv
+	titles, _, err := LoadTitles(strings.NewReader("movieId,title\n1,\"American President, The (1995)\"\nx,y\n"), CSV)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if titles[1] != "American President, The (1995)" {
+		t.Fatalf("title %q", titles[1])
+	}
+
+	_, diagnostics, err := LoadTitles(strings.NewReader("1|Toy Story\n2\nx|y\n"), MovieLens100K)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 2 || diagnostics[0].Line != 2 || diagnostics[1].Line != 3 {
+		t.Fatalf("diagnostics %v, want lines 2 and 3", diagnostics)
+	}
+}
+
+func TestLoadRatingsEmpty(t *testing.T) {
+
+	for _, input := range []string{"", "\n\n", "userId,movieId,rating\n"} {
+
+		ratings := load(t, input, CSV, nil)
+
+		if ratings.Preferences.RowsN() != 0 || ratings.Preferences.ColsN() != 0 || len(ratings.Diagnostics) != 0 {
+			t.Fatalf("%q: %dx%d matrix, diagnostics %v", input, ratings.Preferences.RowsN(), ratings.Preferences.ColsN(), ratings.Diagnostics)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+
+	for path, want := range map[string]Format{
+		"ml-100k/u.data":    MovieLens100K,
+		"ml-100k/u1.base":   MovieLens100K,
+		"ml-1m/ratings.dat": MovieLens1M,
+		"ratings.tsv":       TSV,
+		"ratings.csv":       CSV,
+	} {
+		if got := DetectFormat(path); got != want {
+			t.Fatalf("%s: %d, want %d", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package matrix
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomDense returns a rows×cols matrix with about density of its cells set
+// to non-zero values in [-5, 5], rows with an even index being left empty
+// now and then.
+func randomDense(rng *rand.Rand, rows int, cols int, density float64) *Matrix[float64] {
+
+	m := NewZeroMatrix[float64](rows, cols)
+
+	for i := range rows {
+
+		if i%2 == 0 && rng.Intn(3) == 0 {
+			continue
+		}
+
+		for j := range cols {
+
+			if rng.Float64() < density {
+
+				value := float64(rng.Intn(10) - 5)
+
+				if value == 0 {
+					value = 5
+				}
+				m.data[i][j] = value
+			}
+		}
+	}
+
+	return m
+}
+
+func assertEqualDense[T Numeric](t *testing.T, name string, want *Matrix[T], got *Matrix[T]) {
+
+	t.Helper()
+
+	if want.Rows != got.Rows || want.Cols != got.Cols {
+		t.Fatalf("%s: dims %dx%d, want %dx%d", name, got.Rows, got.Cols, want.Rows, want.Cols)
+	}
+
+	for i := range want.Rows {
+		for j := range want.Cols {
+
+			if want.data[i][j] != got.data[i][j] {
+				t.Fatalf("%s: (%d, %d) = %v, want %v", name, i, j, got.data[i][j], want.data[i][j])
+			}
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+
+	for trial := range 200 {
+
+		rows, cols := 1+rng.Intn(12), 1+rng.Intn(12)
+		dense := randomDense(rng, rows, cols, rng.Float64())
+
+		coo := dense.ToCoordinates()
+		csr := dense.ToCSR()
+		csc := dense.ToCSC()
+		ellpack := dense.ToELLPACK()
+
+		if csr.Nnz() != dense.Nnz() || coo.Nnz() != dense.Nnz() || csc.Nnz() != dense.Nnz() {
+			t.Fatalf("trial %d: nnz %d (COO), %d (CSR), %d (CSC), want %d", trial, coo.Nnz(), csr.Nnz(), csc.Nnz(), dense.Nnz())
+		}
+
+		assertEqualDense(t, "dense → COO → dense", dense, coo.ToDense())
+		assertEqualDense(t, "dense → CSR → dense", dense, csr.ToDense())
+		assertEqualDense(t, "dense → CSC → dense", dense, csc.ToDense())
+		assertEqualDense(t, "dense → ELLPACK → dense", dense, ellpack.ToDense())
+
+		cooCSR := coo.ToCSR()
+		assertEqualDense(t, "COO → CSR → dense", dense, cooCSR.ToDense())
+		cooELLPACK := coo.ToELLPACK()
+		assertEqualDense(t, "COO → ELLPACK → dense", dense, cooELLPACK.ToDense())
+		csrCOO := csr.ToCoordinates()
+		assertEqualDense(t, "CSR → COO → dense", dense, csrCOO.ToDense())
+		csrCSC := csr.ToCSC()
+		assertEqualDense(t, "CSR → CSC → dense", dense, csrCSC.ToDense())
+		cscCSR := csc.ToCSR()
+		assertEqualDense(t, "CSC → CSR → dense", dense, cscCSR.ToDense())
+		cscCOO := csc.ToCoordinates()
+		assertEqualDense(t, "CSC → COO → dense", dense, cscCOO.ToDense())
+		csrELLPACK := csr.ToELLPACK()
+		assertEqualDense(t, "CSR → ELLPACK → dense", dense, csrELLPACK.ToDense())
+		ellpackCSR := ellpack.ToCSR()
+		assertEqualDense(t, "ELLPACK → CSR → dense", dense, ellpackCSR.ToDense())
+
+		if ellpackCSR.Nnz() != dense.Nnz() {
+			t.Fatalf("trial %d: ELLPACK → CSR nnz %d, want %d", trial, ellpackCSR.Nnz(), dense.Nnz())
+		}
+	}
+}
+
+func TestRoundTripEmpty(t *testing.T) {
+
+	dense := NewZeroMatrix[float64](3, 4)
+
+	csr := dense.ToCSR()
+	assertEqualDense(t, "CSR", dense, csr.ToDense())
+	ellpack := dense.ToELLPACK()
+	assertEqualDense(t, "ELLPACK", dense, ellpack.ToDense())
+	coo := dense.ToCoordinates()
+	cooCSR := coo.ToCSR()
+	assertEqualDense(t, "COO → CSR", dense, cooCSR.ToDense())
+}
+
+// ELLPACK pads rows with zeros at column 0, which must not overwrite a
+// negative value stored there.
+func TestELLPACKNegativeValues(t *testing.T) {
+
+	dense := NewMatrix([][]float64{
+		{-1, 0, 0},
+		{0, -2, -3},
+		{-4, 0, 0},
+	})
+
+	ellpack := dense.ToELLPACK()
+	assertEqualDense(t, "ELLPACK → dense", dense, ellpack.ToDense())
+
+	csr := ellpack.ToCSR()
+	assertEqualDense(t, "ELLPACK → CSR → dense", dense, csr.ToDense())
+
+	intDense := NewMatrix([][]int{{0, -7}, {-1, 0}})
+	intELLPACK := intDense.ToELLPACK()
+	assertEqualDense(t, "int ELLPACK → dense", intDense, intELLPACK.ToDense())
+}
+
+func TestCOODuplicates(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(2))
+
+	for range 100 {
+
+		rows, cols := 1+rng.Intn(8), 1+rng.Intn(8)
+		coo := NewCoordinateList[int](rows, cols)
+		want := NewZeroMatrix[int](rows, cols)
+
+		for range rng.Intn(40) {
+
+			i, j, value := rng.Intn(rows), rng.Intn(cols), rng.Intn(11)-5
+
+			coo.Append(i, j, value)
+			want.data[i][j] += value
+		}
+
+		// an entry cancelled by its duplicate is dropped
+		coo.Append(0, 0, 3)
+		coo.Append(0, 0, -3)
+
+		assertEqualDense(t, "COO → dense", want, coo.ToDense())
+
+		csr := coo.ToCSR()
+		assertEqualDense(t, "COO → CSR → dense", want, csr.ToDense())
+
+		if csr.Nnz() != want.Nnz() {
+			t.Fatalf("COO → CSR nnz %d, want %d", csr.Nnz(), want.Nnz())
+		}
+
+		csc := coo.ToCSC()
+		assertEqualDense(t, "COO → CSC → dense", want, csc.ToDense())
+		ellpack := coo.ToELLPACK()
+		assertEqualDense(t, "COO → ELLPACK → dense", want, ellpack.ToDense())
+
+		for row_n := range rows {
+
+			indices, _ := csr.GetSparseRow(row_n)
+
+			for k := 1; k < len(indices); k++ {
+				if indices[k] <= indices[k-1] {
+					t.Fatalf("row %d columns %v aren't sorted", row_n, indices)
+				}
+			}
+		}
+
+		// the accessors sum duplicates the same way
+		assertSameAccess(t, "COO", want, coo)
+
+		// Set replaces the duplicates
+		for range 10 {
+
+			i, j, value := rng.Intn(rows), rng.Intn(cols), rng.Intn(5)-2
+
+			coo.Set(i, j, value)
+			want.data[i][j] = value
+		}
+
+		assertSameAccess(t, "COO after Set", want, coo)
+		assertEqualDense(t, "COO after Set → dense", want, coo.ToDense())
+	}
+}
+
+// assertSameAccess compares every accessor of m with the dense matrix.
+func assertSameAccess[T Numeric](t *testing.T, name string, want *Matrix[T], m IMatrix[T]) {
+
+	t.Helper()
+
+	for i := range want.Rows {
+
+		row := m.GetDenseRow(i)
+		indices, values := m.GetSparseRow(i)
+		_, want_values := want.GetSparseRow(i)
+
+		if len(values) != len(want_values) {
+			t.Fatalf("%s: sparse row %d has %d values, want %d", name, i, len(values), len(want_values))
+		}
+
+		for k, j := range indices {
+			if values[k] != want.data[i][j] {
+				t.Fatalf("%s: sparse row %d: (%d) = %v, want %v", name, i, j, values[k], want.data[i][j])
+			}
+		}
+
+		for j := range want.Cols {
+
+			if got := m.Get(i, j); got != want.data[i][j] {
+				t.Fatalf("%s: Get(%d, %d) = %v, want %v", name, i, j, got, want.data[i][j])
+			}
+
+			if row[j] != want.data[i][j] {
+				t.Fatalf("%s: dense row %d: (%d) = %v, want %v", name, i, j, row[j], want.data[i][j])
+			}
+		}
+	}
+
+	for j := range want.Cols {
+
+		column := m.GetDenseCol(j)
+		indices, values := m.GetSparseCol(j)
+		_, want_values := want.GetSparseCol(j)
+
+		if len(values) != len(want_values) {
+			t.Fatalf("%s: sparse column %d has %d values, want %d", name, j, len(values), len(want_values))
+		}
+
+		for k, i := range indices {
+			if values[k] != want.data[i][j] {
+				t.Fatalf("%s: sparse column %d: (%d) = %v, want %v", name, j, i, values[k], want.data[i][j])
+			}
+		}
+
+		for i := range want.Rows {
+			if column[i] != want.data[i][j] {
+				t.Fatalf("%s: dense column %d: (%d) = %v, want %v", name, j, i, column[i], want.data[i][j])
+			}
+		}
+	}
+}
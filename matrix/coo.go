@@ -0,0 +1,275 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type CoordinateList[T Numeric] struct {
+	Values []T
+	Row    []int
+	Col    []int
+	Rows   int
+	Cols   int
+}
+
+func (m CoordinateList[T]) String() string {
+
+	var sb strings.Builder
+	sb.WriteString("CoordinateList (COO format):\n\n")
+	for i := range m.Values {
+		fmt.Fprintf(&sb, "(%d, %d) -> %v\n", m.Row[i], m.Col[i], m.Values[i])
+	}
+	return sb.String()
+}
+
+func NewCoordinateList[T Numeric](row_n int, col_n int) *CoordinateList[T] {
+
+	return &CoordinateList[T]{Rows: row_n, Cols: col_n}
+}
+
+func (m *CoordinateList[T]) Dims() (int, int) {
+	return m.Rows, m.Cols
+}
+
+// Nnz counts the stored entries, duplicates included.
+func (m *CoordinateList[T]) Nnz() int {
+	return len(m.Values)
+}
+
+// Get sums the entries at the position, the same as the conversions do.
+func (m *CoordinateList[T]) Get(row_n int, col_n int) T {
+
+	var sum T
+
+	for i := range m.Values {
+
+		if m.Row[i] == row_n && m.Col[i] == col_n {
+			sum += m.Values[i]
+		}
+	}
+
+	return sum
+}
+
+// Set replaces all the entries at the position, duplicates included.
+func (m *CoordinateList[T]) Set(row_n int, col_n int, val T) {
+
+	k := 0
+
+	for i := range m.Values {
+
+		if m.Row[i] == row_n && m.Col[i] == col_n {
+			continue
+		}
+
+		m.Values[k], m.Row[k], m.Col[k] = m.Values[i], m.Row[i], m.Col[i]
+		k++
+	}
+
+	m.Values = m.Values[:k]
+	m.Row = m.Row[:k]
+	m.Col = m.Col[:k]
+
+	if val != 0 {
+		m.Append(row_n, col_n, val)
+	}
+}
+
+// Append adds an entry without looking for an existing one at the same
+// position. Duplicates are summed by the accessors and the conversions.
+func (m *CoordinateList[T]) Append(row_n int, col_n int, val T) {
+
+	m.Values = append(m.Values, val)
+	m.Row = append(m.Row, row_n)
+	m.Col = append(m.Col, col_n)
+}
+
+func (m *CoordinateList[T]) GetDenseRow(row_n int) []T {
+
+	row := make([]T, m.Cols)
+
+	for i := range m.Values {
+		if m.Row[i] == row_n {
+			row[m.Col[i]] += m.Values[i]
+		}
+	}
+
+	return row
+}
+
+func (m *CoordinateList[T]) GetDenseCol(col_n int) []T {
+
+	column := make([]T, m.Rows)
+
+	for i := range m.Values {
+		if m.Col[i] == col_n {
+			column[m.Row[i]] += m.Values[i]
+		}
+	}
+
+	return column
+}
+
+// sparseLine returns line n sorted by the other coordinate, duplicates being
+// summed and zero sums dropped.
+func (m *CoordinateList[T]) sparseLine(line []int, other []int, n int) ([]int, []T) {
+
+	positions := []int{}
+
+	for i := range m.Values {
+		if line[i] == n {
+			positions = append(positions, i)
+		}
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		return other[positions[i]] < other[positions[j]]
+	})
+
+	indices := []int{}
+	values := []T{}
+
+	for k := 0; k < len(positions); {
+
+		index := other[positions[k]]
+
+		var sum T
+		for ; k < len(positions) && other[positions[k]] == index; k++ {
+			sum += m.Values[positions[k]]
+		}
+
+		if sum != 0 {
+			indices = append(indices, index)
+			values = append(values, sum)
+		}
+	}
+
+	return indices, values
+}
+
+func (m *CoordinateList[T]) GetSparseRow(row_n int) ([]int, []T) {
+	return m.sparseLine(m.Row, m.Col, row_n)
+}
+
+func (m *CoordinateList[T]) GetSparseCol(col_n int) ([]int, []T) {
+	return m.sparseLine(m.Col, m.Row, col_n)
+}
+
+func (m *CoordinateList[T]) deleteLine(line []int, n int) {
+
+	k := 0
+
+	for i := range m.Values {
+
+		if line[i] == n {
+			continue
+		}
+
+		if line[i] > n {
+			line[i]--
+		}
+
+		m.Values[k], m.Row[k], m.Col[k] = m.Values[i], m.Row[i], m.Col[i]
+		k++
+	}
+
+	m.Values = m.Values[:k]
+	m.Row = m.Row[:k]
+	m.Col = m.Col[:k]
+}
+
+func (m *CoordinateList[T]) DeleteRow(row_n int) error {
+
+	if row_n > m.Rows-1 || row_n < 0 {
+		return errors.New("row number out of range")
+	}
+
+	m.deleteLine(m.Row, row_n)
+	m.Rows--
+
+	return nil
+}
+
+func (m *CoordinateList[T]) DeleteColumn(col_n int) error {
+
+	if col_n > m.Cols-1 || col_n < 0 {
+		return errors.New("column number out of range")
+	}
+
+	m.deleteLine(m.Col, col_n)
+	m.Cols--
+
+	return nil
+}
+
+func (m *CoordinateList[T]) AppendRow() {
+	m.Rows++
+}
+
+func (m *CoordinateList[T]) AppendColumn() {
+	m.Cols++
+}
+
+// ToCSR sorts the entries by row and column, sums duplicates and drops
+// zeros.
+func (m *CoordinateList[T]) ToCSR() CSR[T] {
+
+	order := make([]int, len(m.Values))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(i, j int) int {
+
+		if m.Row[i] != m.Row[j] {
+			return m.Row[i] - m.Row[j]
+		}
+		return m.Col[i] - m.Col[j]
+	})
+
+	csr := CSR[T]{Row_index: make([]int, m.Rows+1), Rows: m.Rows, Cols: m.Cols}
+
+	for k := 0; k < len(order); {
+
+		row_n, col_n := m.Row[order[k]], m.Col[order[k]]
+
+		var sum T
+		for ; k < len(order) && m.Row[order[k]] == row_n && m.Col[order[k]] == col_n; k++ {
+			sum += m.Values[order[k]]
+		}
+
+		if sum != 0 {
+			csr.Values = append(csr.Values, sum)
+			csr.Col = append(csr.Col, col_n)
+			csr.Row_index[row_n+1]++
+		}
+	}
+
+	for row_n := range m.Rows {
+		csr.Row_index[row_n+1] += csr.Row_index[row_n]
+	}
+
+	return csr
+}
+
+func (m *CoordinateList[T]) ToDense() *Matrix[T] {
+
+	dense := NewZeroMatrix[T](m.Rows, m.Cols)
+
+	for i := range m.Values {
+		dense.data[m.Row[i]][m.Col[i]] += m.Values[i]
+	}
+
+	return dense
+}
+
+func (m *CoordinateList[T]) ToELLPACK() ELLPACK[T] {
+
+	csr := m.ToCSR()
+	return csr.ToELLPACK()
+}
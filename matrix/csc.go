@@ -0,0 +1,269 @@
+package matrix
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CSC stores the same arrays as a CSR of the transposed matrix, so most of
+// the methods delegate to CSR through a transposed view.
+type CSC[T Numeric] struct {
+	Values    []T
+	Row       []int
+	Col_index []int
+	Rows      int
+	Cols      int
+}
+
+func (m CSC[T]) String() string {
+
+	var sb strings.Builder
+
+	sb.WriteString("CSC (Compressed Sparse Column) format:\n\n")
+	fmt.Fprintf(&sb, "Values:    %v\n", m.Values)
+	fmt.Fprintf(&sb, "Rows:      %v\n", m.Row)
+	fmt.Fprintf(&sb, "Col_index: %v\n", m.Col_index)
+
+	return sb.String()
+}
+
+func NewCSC[T Numeric](row_n int, col_n int) *CSC[T] {
+
+	return &CSC[T]{
+		Col_index: make([]int, col_n+1),
+		Rows:      row_n,
+		Cols:      col_n}
+}
+
+func (m *CSC[T]) view() *CSR[T] {
+
+	return &CSR[T]{
+		Values:    m.Values,
+		Col:       m.Row,
+		Row_index: m.Col_index,
+		Rows:      m.Cols,
+		Cols:      m.Rows}
+}
+
+func (m *CSC[T]) update(view *CSR[T]) {
+
+	m.Values = view.Values
+	m.Row = view.Col
+	m.Col_index = view.Row_index
+	m.Rows = view.Cols
+	m.Cols = view.Rows
+}
+
+func (m *CSC[T]) Dims() (int, int) {
+	return m.Rows, m.Cols
+}
+
+func (m *CSC[T]) Nnz() int {
+	return len(m.Values)
+}
+
+func (m *CSC[T]) Get(row_n int, col_n int) T {
+	return m.view().Get(col_n, row_n)
+}
+
+func (m *CSC[T]) Set(row_n int, col_n int, val T) {
+
+	view := m.view()
+	view.Set(col_n, row_n, val)
+	m.update(view)
+}
+
+// GetCol returns row indices and values of the non-zero cells of the column.
+// The slices share memory with the matrix and must not be modified.
+func (m *CSC[T]) GetCol(col_n int) ([]int, []T) {
+	return m.view().GetRow(col_n)
+}
+
+func (m *CSC[T]) GetSparseRow(row_n int) ([]int, []T) {
+	return m.view().GetSparseCol(row_n)
+}
+
+func (m *CSC[T]) GetSparseCol(col_n int) ([]int, []T) {
+	return m.GetCol(col_n)
+}
+
+func (m *CSC[T]) GetDenseRow(row_n int) []T {
+	return m.view().GetDenseCol(row_n)
+}
+
+func (m *CSC[T]) GetDenseCol(col_n int) []T {
+	return m.view().GetDenseRow(col_n)
+}
+
+func (m *CSC[T]) DeleteRow(row_n int) error {
+
+	view := m.view()
+	err := view.DeleteColumn(row_n)
+	m.update(view)
+
+	return err
+}
+
+func (m *CSC[T]) DeleteColumn(col_n int) error {
+
+	view := m.view()
+	err := view.DeleteRow(col_n)
+	m.update(view)
+
+	return err
+}
+
+func (m *CSC[T]) AppendRow() {
+	m.Rows++
+}
+
+func (m *CSC[T]) AppendColumn() {
+
+	m.Col_index = append(m.Col_index, len(m.Values))
+	m.Cols++
+}
+
+func (m *CSC[T]) Transpose() *CSC[T] {
+
+	transposed := &CSC[T]{}
+	transposed.update(m.view().Transpose())
+
+	return transposed
+}
+
+func (m *CSC[T]) ToCSR() CSR[T] {
+	return *m.view().Transpose()
+}
+
+func (m *CSC[T]) ToCoordinates() CoordinateList[T] {
+
+	csr := m.ToCSR()
+	return csr.ToCoordinates()
+}
+
+func (m *CSC[T]) ToDense() *Matrix[T] {
+
+	dense := NewZeroMatrix[T](m.Rows, m.Cols)
+
+	for col_n := range m.Cols {
+		for k := m.Col_index[col_n]; k < m.Col_index[col_n+1]; k++ {
+			dense.data[m.Row[k]][col_n] = m.Values[k]
+		}
+	}
+
+	return dense
+}
+
+func (m *CSR[T]) ToCSC() CSC[T] {
+
+	csc := CSC[T]{}
+	csc.update(m.Transpose())
+
+	return csc
+}
+
+func (m *Matrix[T]) ToCSC() CSC[T] {
+
+	csr := m.ToCSR()
+	return csr.ToCSC()
+}
+
+func (m *CoordinateList[T]) ToCSC() CSC[T] {
+
+	csr := m.ToCSR()
+	return csr.ToCSC()
+}
+
+// DualSparse keeps a CSR and a CSC copy of the same matrix in sync, so both
+// row and column access cost O(nnz) of the line instead of a full scan.
+type DualSparse[T Numeric] struct {
+	csr *CSR[T]
+	csc *CSC[T]
+}
+
+func (m DualSparse[T]) String() string {
+	return m.csr.String() + "\n" + m.csc.String()
+}
+
+// NewDualSparse copies csr, so the caller's slices aren't changed by later
+// updates.
+func NewDualSparse[T Numeric](csr CSR[T]) *DualSparse[T] {
+
+	csr.Values = slices.Clone(csr.Values)
+	csr.Col = slices.Clone(csr.Col)
+	csr.Row_index = slices.Clone(csr.Row_index)
+
+	csc := csr.ToCSC()
+	return &DualSparse[T]{csr: &csr, csc: &csc}
+}
+
+func (m *DualSparse[T]) CSR() *CSR[T] {
+	return m.csr
+}
+
+func (m *DualSparse[T]) CSC() *CSC[T] {
+	return m.csc
+}
+
+func (m *DualSparse[T]) Dims() (int, int) {
+	return m.csr.Dims()
+}
+
+func (m *DualSparse[T]) Nnz() int {
+	return m.csr.Nnz()
+}
+
+func (m *DualSparse[T]) Get(row_n int, col_n int) T {
+	return m.csr.Get(row_n, col_n)
+}
+
+func (m *DualSparse[T]) Set(row_n int, col_n int, val T) {
+
+	m.csr.Set(row_n, col_n, val)
+	m.csc.Set(row_n, col_n, val)
+}
+
+func (m *DualSparse[T]) GetSparseRow(row_n int) ([]int, []T) {
+	return m.csr.GetRow(row_n)
+}
+
+func (m *DualSparse[T]) GetSparseCol(col_n int) ([]int, []T) {
+	return m.csc.GetCol(col_n)
+}
+
+func (m *DualSparse[T]) GetDenseRow(row_n int) []T {
+	return m.csr.GetDenseRow(row_n)
+}
+
+func (m *DualSparse[T]) GetDenseCol(col_n int) []T {
+	return m.csc.GetDenseCol(col_n)
+}
+
+func (m *DualSparse[T]) DeleteRow(row_n int) error {
+
+	if err := m.csr.DeleteRow(row_n); err != nil {
+		return err
+	}
+	return m.csc.DeleteRow(row_n)
+}
+
+func (m *DualSparse[T]) DeleteColumn(col_n int) error {
+
+	if err := m.csr.DeleteColumn(col_n); err != nil {
+		return err
+	}
+	return m.csc.DeleteColumn(col_n)
+}
+
+func (m *DualSparse[T]) AppendRow() {
+
+	m.csr.AppendRow()
+	m.csc.AppendRow()
+}
+
+func (m *DualSparse[T]) AppendColumn() {
+
+	m.csr.AppendColumn()
+	m.csc.AppendColumn()
+}
@@ -0,0 +1,82 @@
+package matrix
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDualSparseStaysInSync(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+
+	for trial := range 20 {
+
+		dense := randomDense(rng, 1+rng.Intn(8), 1+rng.Intn(8), 0.3)
+		dual := NewDualSparse(dense.ToCSR())
+
+		for step := range 200 {
+
+			rows, cols := dense.Dims()
+
+			switch op := rng.Intn(10); {
+			case op < 5 && rows > 0 && cols > 0:
+				i, j, value := rng.Intn(rows), rng.Intn(cols), float64(rng.Intn(5)-2)
+				dense.Set(i, j, value)
+				dual.Set(i, j, value)
+
+			case op == 5:
+				dense.AppendRow()
+				dual.AppendRow()
+
+			case op == 6:
+				dense.AppendColumn()
+				dual.AppendColumn()
+
+			case op == 7 && rows > 1:
+				i := rng.Intn(rows)
+
+				if err := dual.DeleteRow(i); err != nil {
+					t.Fatal(err)
+				}
+				dense.DeleteRow(i)
+
+			case op == 8 && cols > 1:
+				j := rng.Intn(cols)
+
+				if err := dual.DeleteColumn(j); err != nil {
+					t.Fatal(err)
+				}
+				dense.DeleteColumn(j)
+			}
+
+			assertWellFormed(t, dual.CSR())
+			assertWellFormed(t, dual.CSC().view())
+			assertEqualDense(t, "CSR half", dense, dual.CSR().ToDense())
+			assertEqualDense(t, "CSC half", dense, dual.CSC().ToDense())
+
+			if dual.CSR().Nnz() != dual.CSC().Nnz() {
+				t.Fatalf("trial %d, step %d: nnz %d (CSR) and %d (CSC)", trial, step, dual.CSR().Nnz(), dual.CSC().Nnz())
+			}
+		}
+
+		assertSameAccess(t, "DualSparse", dense, dual)
+	}
+}
+
+func TestNewDualSparseCopies(t *testing.T) {
+
+	dense := NewMatrix([][]float64{{1, 0, 2}, {0, 3, 0}})
+	csr := dense.ToCSR()
+
+	dual := NewDualSparse(csr)
+	dual.Set(0, 0, 5)
+	dual.Set(0, 2, 0)
+
+	assertEqualDense(t, "caller's CSR", dense, csr.ToDense())
+
+	csr.Values[0] = 7
+
+	if dual.Get(0, 0) != 5 {
+		t.Fatalf("(0, 0) = %v after changing the caller's CSR, want 5", dual.Get(0, 0))
+	}
+}
@@ -0,0 +1,392 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type CSR[T Numeric] struct {
+	Values    []T
+	Col       []int
+	Row_index []int
+	Rows      int
+	Cols      int
+}
+
+func (m CSR[T]) String() string {
+
+	var sb strings.Builder
+
+	sb.WriteString("CSR (Compressed Sparse Row) format:\n\n")
+	fmt.Fprintf(&sb, "Values:    %v\n", m.Values)
+	fmt.Fprintf(&sb, "Columns:   %v\n", m.Col)
+	fmt.Fprintf(&sb, "Row_index: %v\n", m.Row_index)
+
+	return sb.String()
+}
+
+func NewCSR[T Numeric](row_n int, col_n int) *CSR[T] {
+
+	return &CSR[T]{
+		Row_index: make([]int, row_n+1),
+		Rows:      row_n,
+		Cols:      col_n}
+}
+
+func (m *CSR[T]) Dims() (int, int) {
+	return m.Rows, m.Cols
+}
+
+func (m *CSR[T]) Nnz() int {
+	return len(m.Values)
+}
+
+// position returns the index of (row_n, col_n) inside Values/Col and whether
+// the cell is stored; when it is not, the index is where it would be inserted.
+func (m *CSR[T]) position(row_n int, col_n int) (int, bool) {
+
+	start, end := m.Row_index[row_n], m.Row_index[row_n+1]
+	k := start + sort.SearchInts(m.Col[start:end], col_n)
+
+	return k, k < end && m.Col[k] == col_n
+}
+
+func (m *CSR[T]) Get(row_n int, col_n int) T {
+
+	if k, ok := m.position(row_n, col_n); ok {
+		return m.Values[k]
+	}
+
+	var zero T
+	return zero
+}
+
+func (m *CSR[T]) Set(row_n int, col_n int, val T) {
+
+	k, ok := m.position(row_n, col_n)
+
+	switch {
+	case ok && val != 0:
+		m.Values[k] = val
+		return
+
+	case ok:
+		m.Values = slices.Delete(m.Values, k, k+1)
+		m.Col = slices.Delete(m.Col, k, k+1)
+
+		for i := row_n + 1; i <= m.Rows; i++ {
+			m.Row_index[i]--
+		}
+
+	case val != 0:
+		m.Values = slices.Insert(m.Values, k, val)
+		m.Col = slices.Insert(m.Col, k, col_n)
+
+		for i := row_n + 1; i <= m.Rows; i++ {
+			m.Row_index[i]++
+		}
+	}
+}
+
+// GetRow returns column indices and values of the non-zero cells of the row.
+// The slices share memory with the matrix and must not be modified.
+func (m *CSR[T]) GetRow(row_n int) ([]int, []T) {
+
+	start, end := m.Row_index[row_n], m.Row_index[row_n+1]
+
+	return m.Col[start:end], m.Values[start:end]
+}
+
+func (m *CSR[T]) GetSparseRow(row_n int) ([]int, []T) {
+	return m.GetRow(row_n)
+}
+
+func (m *CSR[T]) GetSparseCol(col_n int) ([]int, []T) {
+
+	indices := []int{}
+	values := []T{}
+
+	for row_n := range m.Rows {
+
+		if k, ok := m.position(row_n, col_n); ok {
+			indices = append(indices, row_n)
+			values = append(values, m.Values[k])
+		}
+	}
+
+	return indices, values
+}
+
+func (m *CSR[T]) GetDenseRow(row_n int) []T {
+
+	row := make([]T, m.Cols)
+	cols, values := m.GetRow(row_n)
+
+	for k, col_n := range cols {
+		row[col_n] = values[k]
+	}
+
+	return row
+}
+
+func (m *CSR[T]) GetDenseCol(col_n int) []T {
+
+	column := make([]T, m.Rows)
+
+	for row_n := range m.Rows {
+		column[row_n] = m.Get(row_n, col_n)
+	}
+
+	return column
+}
+
+func (m *CSR[T]) DeleteRow(row_n int) error {
+
+	if row_n > m.Rows-1 || row_n < 0 {
+		return errors.New("row number out of range")
+	}
+
+	start, end := m.Row_index[row_n], m.Row_index[row_n+1]
+
+	m.Values = slices.Delete(m.Values, start, end)
+	m.Col = slices.Delete(m.Col, start, end)
+	m.Row_index = slices.Delete(m.Row_index, row_n+1, row_n+2)
+
+	for i := row_n + 1; i < len(m.Row_index); i++ {
+		m.Row_index[i] -= end - start
+	}
+	m.Rows--
+
+	return nil
+}
+
+func (m *CSR[T]) DeleteColumn(col_n int) error {
+
+	if col_n > m.Cols-1 || col_n < 0 {
+		return errors.New("column number out of range")
+	}
+
+	n := 0
+
+	for row_n := range m.Rows {
+
+		start, end := m.Row_index[row_n], m.Row_index[row_n+1]
+		m.Row_index[row_n] = n
+
+		for k := start; k < end; k++ {
+
+			switch {
+			case m.Col[k] == col_n:
+				continue
+			case m.Col[k] > col_n:
+				m.Col[n] = m.Col[k] - 1
+			default:
+				m.Col[n] = m.Col[k]
+			}
+			m.Values[n] = m.Values[k]
+			n++
+		}
+	}
+
+	m.Row_index[m.Rows] = n
+	m.Values = m.Values[:n]
+	m.Col = m.Col[:n]
+	m.Cols--
+
+	return nil
+}
+
+func (m *CSR[T]) AppendRow() {
+
+	m.Row_index = append(m.Row_index, len(m.Values))
+	m.Rows++
+}
+
+func (m *CSR[T]) AppendColumn() {
+	m.Cols++
+}
+
+func (m *CSR[T]) Transpose() *CSR[T] {
+
+	transposed := &CSR[T]{
+		Values:    make([]T, len(m.Values)),
+		Col:       make([]int, len(m.Col)),
+		Row_index: make([]int, m.Cols+1),
+		Rows:      m.Cols,
+		Cols:      m.Rows}
+
+	for _, col_n := range m.Col {
+		transposed.Row_index[col_n+1]++
+	}
+
+	for i := range m.Cols {
+		transposed.Row_index[i+1] += transposed.Row_index[i]
+	}
+
+	next := slices.Clone(transposed.Row_index[:m.Cols])
+
+	for row_n := range m.Rows {
+		for k := m.Row_index[row_n]; k < m.Row_index[row_n+1]; k++ {
+
+			dest := next[m.Col[k]]
+			transposed.Values[dest] = m.Values[k]
+			transposed.Col[dest] = row_n
+			next[m.Col[k]]++
+		}
+	}
+
+	return transposed
+}
+
+func (m *CSR[T]) MulVec(vector []T) ([]T, error) {
+
+	if len(vector) != m.Cols {
+		return nil, errors.New("vector length doesn't equal to matrix columns amount")
+	}
+
+	result := make([]T, m.Rows)
+
+	for row_n := range m.Rows {
+
+		var sum T
+
+		for k := m.Row_index[row_n]; k < m.Row_index[row_n+1]; k++ {
+			sum += m.Values[k] * vector[m.Col[k]]
+		}
+		result[row_n] = sum
+	}
+
+	return result, nil
+}
+
+func (m *CSR[T]) Mul(other *CSR[T]) (*CSR[T], error) {
+
+	if m.Cols != other.Rows {
+		return nil, errors.New("matrix columns amount doesn't equal to other matrix rows amount")
+	}
+
+	result := NewCSR[T](m.Rows, other.Cols)
+
+	accumulator := make([]T, other.Cols)
+	touched := make([]bool, other.Cols)
+	row_cols := []int{}
+
+	for row_n := range m.Rows {
+
+		row_cols = row_cols[:0]
+
+		for k := m.Row_index[row_n]; k < m.Row_index[row_n+1]; k++ {
+
+			inner := m.Col[k]
+
+			for j := other.Row_index[inner]; j < other.Row_index[inner+1]; j++ {
+
+				col_n := other.Col[j]
+
+				if !touched[col_n] {
+					touched[col_n] = true
+					row_cols = append(row_cols, col_n)
+				}
+				accumulator[col_n] += m.Values[k] * other.Values[j]
+			}
+		}
+
+		slices.Sort(row_cols)
+
+		for _, col_n := range row_cols {
+
+			if accumulator[col_n] != 0 {
+				result.Values = append(result.Values, accumulator[col_n])
+				result.Col = append(result.Col, col_n)
+			}
+			accumulator[col_n] = 0
+			touched[col_n] = false
+		}
+		result.Row_index[row_n+1] = len(result.Values)
+	}
+
+	return result, nil
+}
+
+// MulDense multiplies by a dense matrix, e.g. a block of vectors.
+func (m *CSR[T]) MulDense(other *Matrix[T]) (*Matrix[T], error) {
+
+	if m.Cols != other.Rows {
+		return nil, errors.New("matrix columns amount doesn't equal to other matrix rows amount")
+	}
+
+	result := NewZeroMatrix[T](m.Rows, other.Cols)
+
+	for row_n := range m.Rows {
+
+		row := result.data[row_n]
+
+		for k := m.Row_index[row_n]; k < m.Row_index[row_n+1]; k++ {
+			for j, item := range other.data[m.Col[k]] {
+				row[j] += m.Values[k] * item
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (m *CSR[T]) ToCoordinates() CoordinateList[T] {
+
+	cl := CoordinateList[T]{
+		Values: slices.Clone(m.Values),
+		Row:    make([]int, 0, len(m.Values)),
+		Col:    slices.Clone(m.Col),
+		Rows:   m.Rows,
+		Cols:   m.Cols}
+
+	for row_n := range m.Rows {
+		for k := m.Row_index[row_n]; k < m.Row_index[row_n+1]; k++ {
+			cl.Row = append(cl.Row, row_n)
+		}
+	}
+
+	return cl
+}
+
+func (m *CSR[T]) ToDense() *Matrix[T] {
+
+	dense := NewZeroMatrix[T](m.Rows, m.Cols)
+
+	for row_n := range m.Rows {
+		for k := m.Row_index[row_n]; k < m.Row_index[row_n+1]; k++ {
+			dense.data[row_n][m.Col[k]] = m.Values[k]
+		}
+	}
+
+	return dense
+}
+
+func (m *CSR[T]) ToELLPACK() ELLPACK[T] {
+
+	max_row_len := 0
+
+	for row_n := range m.Rows {
+		max_row_len = max(max_row_len, m.Row_index[row_n+1]-m.Row_index[row_n])
+	}
+
+	ellpack := ELLPACK[T]{
+		Value: *NewZeroMatrix[T](m.Rows, max_row_len),
+		Index: *NewZeroMatrix[uint](m.Rows, max_row_len),
+		Cols:  m.Cols}
+
+	for row_n := range m.Rows {
+
+		start := m.Row_index[row_n]
+
+		for k := start; k < m.Row_index[row_n+1]; k++ {
+			ellpack.Value.data[row_n][k-start] = m.Values[k]
+			ellpack.Index.data[row_n][k-start] = uint(m.Col[k])
+		}
+	}
+
+	return ellpack
+}
@@ -0,0 +1,195 @@
+package matrix
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// assertWellFormed checks that every row keeps its columns ascending and no
+// zero is stored.
+func assertWellFormed[T Numeric](t *testing.T, m *CSR[T]) {
+
+	t.Helper()
+
+	if len(m.Row_index) != m.Rows+1 || m.Row_index[m.Rows] != len(m.Values) || len(m.Col) != len(m.Values) {
+		t.Fatalf("broken structure: %d rows, row index %v, %d columns, %d values", m.Rows, m.Row_index, len(m.Col), len(m.Values))
+	}
+
+	for row_n := range m.Rows {
+		for k := m.Row_index[row_n]; k < m.Row_index[row_n+1]; k++ {
+
+			if m.Values[k] == 0 {
+				t.Fatalf("row %d stores a zero at column %d", row_n, m.Col[k])
+			}
+
+			if k > m.Row_index[row_n] && m.Col[k] <= m.Col[k-1] {
+				t.Fatalf("row %d columns aren't ascending: %v", row_n, m.Col[m.Row_index[row_n]:m.Row_index[row_n+1]])
+			}
+		}
+	}
+}
+
+func TestCSRMulVec(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+
+	for range 50 {
+
+		rows, cols := 1+rng.Intn(10), 1+rng.Intn(10)
+		dense := randomDense(rng, rows, cols, rng.Float64())
+		csr := dense.ToCSR()
+
+		vector := make([]float64, cols)
+
+		for i := range vector {
+			vector[i] = float64(rng.Intn(11) - 5)
+		}
+
+		want, err := dense.MulVec(vector)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := csr.MulVec(vector)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("(%d) = %v, want %v", i, got[i], want[i])
+			}
+		}
+	}
+
+	csr := NewCSR[float64](2, 3)
+
+	if _, err := csr.MulVec([]float64{1, 2}); err == nil {
+		t.Fatal("expected an error for a vector of length 2")
+	}
+}
+
+func TestCSRMul(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(2))
+
+	for range 50 {
+
+		rows, inner, cols := 1+rng.Intn(10), 1+rng.Intn(10), 1+rng.Intn(10)
+		a := randomDense(rng, rows, inner, rng.Float64())
+		b := randomDense(rng, inner, cols, rng.Float64())
+
+		want, err := a.Mul(b)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		a_csr, b_csr := a.ToCSR(), b.ToCSR()
+		got, err := a_csr.Mul(&b_csr)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// products cancelling to 0 must not be stored
+		assertWellFormed(t, got)
+		assertEqualDense(t, "CSR · CSR", want, got.ToDense())
+
+		got_dense, err := a_csr.MulDense(b)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualDense(t, "CSR · dense", want, got_dense)
+	}
+
+	a, b := NewCSR[float64](2, 3), NewCSR[float64](2, 3)
+
+	if _, err := a.Mul(b); err == nil {
+		t.Fatal("expected an error for 2x3 · 2x3")
+	}
+}
+
+func TestCSRTranspose(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(3))
+
+	for range 50 {
+
+		dense := randomDense(rng, 1+rng.Intn(10), 1+rng.Intn(10), rng.Float64())
+		csr := dense.ToCSR()
+		transposed := csr.Transpose()
+
+		assertWellFormed(t, transposed)
+		assertEqualDense(t, "transpose", dense.Transpose(), transposed.ToDense())
+	}
+}
+
+func TestCSRSet(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(4))
+
+	dense := randomDense(rng, 8, 9, 0.3)
+	csr := dense.ToCSR()
+
+	for range 500 {
+
+		row_n, col_n := rng.Intn(8), rng.Intn(9)
+
+		// zeros remove stored cells
+		value := float64(rng.Intn(5) - 2)
+
+		dense.Set(row_n, col_n, value)
+		csr.Set(row_n, col_n, value)
+
+		if csr.Get(row_n, col_n) != value {
+			t.Fatalf("Get(%d, %d) = %v after setting %v", row_n, col_n, csr.Get(row_n, col_n), value)
+		}
+	}
+
+	assertWellFormed(t, &csr)
+	assertEqualDense(t, "Set", dense, csr.ToDense())
+
+	if csr.Nnz() != dense.Nnz() {
+		t.Fatalf("nnz %d, want %d", csr.Nnz(), dense.Nnz())
+	}
+}
+
+func TestCSRDelete(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(5))
+
+	dense := randomDense(rng, 10, 10, 0.4)
+	csr := dense.ToCSR()
+
+	for dense.Rows > 0 && dense.Cols > 0 {
+
+		var err_dense, err_csr error
+
+		if rng.Intn(2) == 0 {
+			row_n := rng.Intn(dense.Rows)
+			err_dense, err_csr = dense.DeleteRow(row_n), csr.DeleteRow(row_n)
+		} else {
+			col_n := rng.Intn(dense.Cols)
+			err_dense, err_csr = dense.DeleteColumn(col_n), csr.DeleteColumn(col_n)
+		}
+
+		if err_dense != nil || err_csr != nil {
+			t.Fatal(err_dense, err_csr)
+		}
+
+		assertWellFormed(t, &csr)
+		assertEqualDense(t, "delete", dense, csr.ToDense())
+	}
+
+	if err := csr.DeleteRow(csr.Rows); err == nil {
+		t.Fatal("expected an error for a row out of range")
+	}
+
+	if err := csr.DeleteColumn(-1); err == nil {
+		t.Fatal("expected an error for a column out of range")
+	}
+}
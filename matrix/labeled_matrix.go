@@ -1,88 +1,316 @@
-package matrix
-
-import (
-	"errors"
-)
-
-type KeyedMatrix[T Numeric, K1 comparable, K2 comparable] struct {
-	matrix Matrix[T]
-
-	RowKeyToIndex map[K1]int
-	ColKeyToIndex map[K2]int
-
-	RowKeys []K1
-	ColKeys []K2
-}
-
-func NewKeyedMatrix[T Numeric, K1 comparable, K2 comparable](matrix Matrix[T], rowKeys []K1, colKeys []K2) (*KeyedMatrix[T, K1, K2], error) {
-
-	if matrix.Rows != len(rowKeys) {
-		return nil, errors.New("Matrix rows amount does't equal to row labels length")
-	}
-
-	if matrix.Cols != len(colKeys) {
-		return nil, errors.New("Matrix columns amount does't equal to column labels length")
-	}
-
-	km := &KeyedMatrix[T, K1, K2]{
-
-		matrix:        matrix,
-		RowKeyToIndex: make(map[K1]int),
-		ColKeyToIndex: make(map[K2]int),
-		RowKeys:       make([]K1, matrix.Rows),
-		ColKeys:       make([]K2, matrix.Cols)}
-
-	for i, rl := range rowKeys {
-		km.RowKeyToIndex[rl] = i
-		km.RowKeys[i] = rl
-	}
-
-	for i, cl := range colKeys {
-		km.ColKeyToIndex[cl] = i
-		km.ColKeys[i] = cl
-	}
-
-	return km, nil
-}
-
-func (im *KeyedMatrix[T, K1, K2]) RowsN() int {
-	return im.matrix.Rows
-}
-
-func (im *KeyedMatrix[T, K1, K2]) ColsN() int {
-	return im.matrix.Cols
-}
-
-func (lm *KeyedMatrix[T, K1, K2]) Get(row_n int, col_n int) T {
-
-	return lm.matrix.Get(row_n, col_n)
-}
-
-func (lm *KeyedMatrix[T, K1, K2]) GetByKey(row_key K1, col_key K2) T {
-	return lm.matrix.Get(lm.RowKeyToIndex[row_key], lm.ColKeyToIndex[col_key])
-}
-
-func (lm *KeyedMatrix[T, K1, K2]) Set(row_n int, col_n int, val T) {
-
-	lm.matrix.Set(row_n, col_n, val)
-}
-
-func (lm *KeyedMatrix[T, K1, K2]) SetByKey(row_key K1, col_key K2, val T) {
-	lm.matrix.Set(lm.RowKeyToIndex[row_key], lm.ColKeyToIndex[col_key], val)
-}
-
-func (lm *KeyedMatrix[T, K1, K2]) GetRow(row_n int) []T {
-	return lm.matrix.GetRow(row_n)
-}
-
-func (lm *KeyedMatrix[T, K1, K2]) GetRowByKey(row_key K1) []T {
-	return lm.matrix.GetRow(lm.RowKeyToIndex[row_key])
-}
-
-func (lm *KeyedMatrix[T, K1, K2]) GetCol(col_n int) []T {
-	return lm.matrix.GetCol(col_n)
-}
-
-func (lm *KeyedMatrix[T, K1, K2]) GetColByKey(col_key K2) []T {
-	return lm.matrix.GetCol(lm.ColKeyToIndex[col_key])
-}
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrUnknownRowKey = errors.New("unknown row key")
+	ErrUnknownColKey = errors.New("unknown column key")
+)
+
+type KeyedMatrix[T Numeric, K1 comparable, K2 comparable] struct {
+	matrix IMatrix[T]
+
+	RowKeyToIndex map[K1]int
+	ColKeyToIndex map[K2]int
+
+	RowKeys []K1
+	ColKeys []K2
+}
+
+func NewKeyedMatrix[T Numeric, K1 comparable, K2 comparable](matrix IMatrix[T], rowKeys []K1, colKeys []K2) (*KeyedMatrix[T, K1, K2], error) {
+
+	rows, cols := matrix.Dims()
+
+	if rows != len(rowKeys) {
+		return nil, errors.New("Matrix rows amount does't equal to row labels length")
+	}
+
+	if cols != len(colKeys) {
+		return nil, errors.New("Matrix columns amount does't equal to column labels length")
+	}
+
+	km := &KeyedMatrix[T, K1, K2]{
+
+		matrix:        matrix,
+		RowKeyToIndex: make(map[K1]int),
+		ColKeyToIndex: make(map[K2]int),
+		RowKeys:       make([]K1, rows),
+		ColKeys:       make([]K2, cols)}
+
+	for i, rl := range rowKeys {
+		km.RowKeyToIndex[rl] = i
+		km.RowKeys[i] = rl
+	}
+
+	for i, cl := range colKeys {
+		km.ColKeyToIndex[cl] = i
+		km.ColKeys[i] = cl
+	}
+
+	return km, nil
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) Matrix() IMatrix[T] {
+	return lm.matrix
+}
+
+func (im *KeyedMatrix[T, K1, K2]) RowsN() int {
+	rows, _ := im.matrix.Dims()
+	return rows
+}
+
+func (im *KeyedMatrix[T, K1, K2]) ColsN() int {
+	_, cols := im.matrix.Dims()
+	return cols
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) Get(row_n int, col_n int) T {
+
+	return lm.matrix.Get(row_n, col_n)
+}
+
+// GetByKey returns 0 when either key is unknown.
+//
+// Deprecated: an unknown key can't be told apart from a stored 0, use
+// LookupByKey, or RowIndex and ColIndex.
+func (lm *KeyedMatrix[T, K1, K2]) GetByKey(row_key K1, col_key K2) T {
+
+	val, _ := lm.LookupByKey(row_key, col_key)
+	return val
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) LookupByKey(row_key K1, col_key K2) (T, bool) {
+
+	row_n, row_ok := lm.RowKeyToIndex[row_key]
+	col_n, col_ok := lm.ColKeyToIndex[col_key]
+
+	if !row_ok || !col_ok {
+		var zero T
+		return zero, false
+	}
+
+	return lm.matrix.Get(row_n, col_n), true
+}
+
+// RowIndex returns an error wrapping ErrUnknownRowKey for unknown keys.
+func (lm *KeyedMatrix[T, K1, K2]) RowIndex(row_key K1) (int, error) {
+
+	row_n, ok := lm.RowKeyToIndex[row_key]
+
+	if !ok {
+		return 0, fmt.Errorf("%w %v", ErrUnknownRowKey, row_key)
+	}
+
+	return row_n, nil
+}
+
+// ColIndex returns an error wrapping ErrUnknownColKey for unknown keys.
+func (lm *KeyedMatrix[T, K1, K2]) ColIndex(col_key K2) (int, error) {
+
+	col_n, ok := lm.ColKeyToIndex[col_key]
+
+	if !ok {
+		return 0, fmt.Errorf("%w %v", ErrUnknownColKey, col_key)
+	}
+
+	return col_n, nil
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) Set(row_n int, col_n int, val T) {
+
+	lm.matrix.Set(row_n, col_n, val)
+}
+
+// SetByKey returns an error wrapping ErrUnknownRowKey or ErrUnknownColKey
+// for unknown keys, leaving the matrix unchanged.
+func (lm *KeyedMatrix[T, K1, K2]) SetByKey(row_key K1, col_key K2, val T) error {
+
+	row_n, err := lm.RowIndex(row_key)
+
+	if err != nil {
+		return err
+	}
+
+	col_n, err := lm.ColIndex(col_key)
+
+	if err != nil {
+		return err
+	}
+
+	lm.matrix.Set(row_n, col_n, val)
+
+	return nil
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) GetRow(row_n int) []T {
+	return lm.matrix.GetDenseRow(row_n)
+}
+
+// GetRowByKey returns nil for an unknown key.
+//
+// Deprecated: use LookupRowByKey or RowIndex.
+func (lm *KeyedMatrix[T, K1, K2]) GetRowByKey(row_key K1) []T {
+
+	row, _ := lm.LookupRowByKey(row_key)
+	return row
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) LookupRowByKey(row_key K1) ([]T, bool) {
+
+	row_n, ok := lm.RowKeyToIndex[row_key]
+
+	if !ok {
+		return nil, false
+	}
+
+	return lm.matrix.GetDenseRow(row_n), true
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) GetCol(col_n int) []T {
+	return lm.matrix.GetDenseCol(col_n)
+}
+
+// GetColByKey returns nil for an unknown key.
+//
+// Deprecated: use LookupColByKey or ColIndex.
+func (lm *KeyedMatrix[T, K1, K2]) GetColByKey(col_key K2) []T {
+
+	col, _ := lm.LookupColByKey(col_key)
+	return col
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) LookupColByKey(col_key K2) ([]T, bool) {
+
+	col_n, ok := lm.ColKeyToIndex[col_key]
+
+	if !ok {
+		return nil, false
+	}
+
+	return lm.matrix.GetDenseCol(col_n), true
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) Nnz() int {
+	return lm.matrix.Nnz()
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) GetSparseRow(row_n int) ([]int, []T) {
+	return lm.matrix.GetSparseRow(row_n)
+}
+
+// GetSparseRowByKey returns nil slices for an unknown key.
+func (lm *KeyedMatrix[T, K1, K2]) GetSparseRowByKey(row_key K1) ([]int, []T) {
+
+	row_n, ok := lm.RowKeyToIndex[row_key]
+
+	if !ok {
+		return nil, nil
+	}
+
+	return lm.matrix.GetSparseRow(row_n)
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) GetSparseCol(col_n int) ([]int, []T) {
+	return lm.matrix.GetSparseCol(col_n)
+}
+
+// GetSparseColByKey returns nil slices for an unknown key.
+func (lm *KeyedMatrix[T, K1, K2]) GetSparseColByKey(col_key K2) ([]int, []T) {
+
+	col_n, ok := lm.ColKeyToIndex[col_key]
+
+	if !ok {
+		return nil, nil
+	}
+
+	return lm.matrix.GetSparseCol(col_n)
+}
+
+// AddRowKey appends an empty row labeled row_key.
+func (lm *KeyedMatrix[T, K1, K2]) AddRowKey(row_key K1) error {
+
+	if _, ok := lm.RowKeyToIndex[row_key]; ok {
+		return fmt.Errorf("row key %v already exists", row_key)
+	}
+
+	lm.matrix.AppendRow()
+	lm.RowKeyToIndex[row_key] = len(lm.RowKeys)
+	lm.RowKeys = append(lm.RowKeys, row_key)
+
+	return nil
+}
+
+// AddColKey appends an empty column labeled col_key.
+func (lm *KeyedMatrix[T, K1, K2]) AddColKey(col_key K2) error {
+
+	if _, ok := lm.ColKeyToIndex[col_key]; ok {
+		return fmt.Errorf("column key %v already exists", col_key)
+	}
+
+	lm.matrix.AppendColumn()
+	lm.ColKeyToIndex[col_key] = len(lm.ColKeys)
+	lm.ColKeys = append(lm.ColKeys, col_key)
+
+	return nil
+}
+
+// DeleteRow removes the row and shifts the indices of the following row keys.
+func (lm *KeyedMatrix[T, K1, K2]) DeleteRow(row_n int) error {
+
+	if err := lm.matrix.DeleteRow(row_n); err != nil {
+		return err
+	}
+
+	delete(lm.RowKeyToIndex, lm.RowKeys[row_n])
+	lm.RowKeys = slices.Delete(lm.RowKeys, row_n, row_n+1)
+
+	for i := row_n; i < len(lm.RowKeys); i++ {
+		lm.RowKeyToIndex[lm.RowKeys[i]] = i
+	}
+
+	return nil
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) DeleteRowByKey(row_key K1) error {
+
+	row_n, err := lm.RowIndex(row_key)
+
+	if err != nil {
+		return err
+	}
+
+	return lm.DeleteRow(row_n)
+}
+
+// DeleteColumn removes the column and shifts the indices of the following
+// column keys.
+func (lm *KeyedMatrix[T, K1, K2]) DeleteColumn(col_n int) error {
+
+	if err := lm.matrix.DeleteColumn(col_n); err != nil {
+		return err
+	}
+
+	delete(lm.ColKeyToIndex, lm.ColKeys[col_n])
+	lm.ColKeys = slices.Delete(lm.ColKeys, col_n, col_n+1)
+
+	for i := col_n; i < len(lm.ColKeys); i++ {
+		lm.ColKeyToIndex[lm.ColKeys[i]] = i
+	}
+
+	return nil
+}
+
+func (lm *KeyedMatrix[T, K1, K2]) DeleteColByKey(col_key K2) error {
+
+	col_n, err := lm.ColIndex(col_key)
+
+	if err != nil {
+		return err
+	}
+
+	return lm.DeleteColumn(col_n)
+}
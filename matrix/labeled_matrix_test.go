@@ -0,0 +1,130 @@
+package matrix
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetByKeyUnknownKey(t *testing.T) {
+
+	m, err := NewKeyedMatrix[int](NewZeroMatrix[int](2, 2), []string{"a", "b"}, []int{1, 2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetByKey("c", 1, 5); !errors.Is(err, ErrUnknownRowKey) {
+		t.Fatalf("unknown row key: err = %v, want ErrUnknownRowKey", err)
+	}
+
+	if err := m.SetByKey("a", 3, 5); !errors.Is(err, ErrUnknownColKey) {
+		t.Fatalf("unknown column key: err = %v, want ErrUnknownColKey", err)
+	}
+
+	if m.Nnz() != 0 {
+		t.Fatalf("a failed SetByKey changed the matrix: %v", m.Matrix())
+	}
+
+	if err := m.SetByKey("b", 2, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.Get(1, 1); got != 5 {
+		t.Fatalf("(b, 2) = %d, want 5", got)
+	}
+}
+
+func TestAddKeys(t *testing.T) {
+
+	m, err := NewKeyedMatrix[int](NewCSR[int](1, 1), []string{"a"}, []int{1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.AddRowKey("a"); err == nil {
+		t.Fatal("expected an error for a duplicate row key")
+	}
+
+	if err := m.AddColKey(1); err == nil {
+		t.Fatal("expected an error for a duplicate column key")
+	}
+
+	if m.RowsN() != 1 || m.ColsN() != 1 {
+		t.Fatalf("a rejected key changed the matrix to %dx%d", m.RowsN(), m.ColsN())
+	}
+
+	if err := m.AddRowKey("b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.AddColKey(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.RowsN() != 2 || m.ColsN() != 2 || m.RowKeyToIndex["b"] != 1 || m.ColKeyToIndex[2] != 1 {
+		t.Fatalf("%dx%d matrix, row keys %v, column keys %v", m.RowsN(), m.ColsN(), m.RowKeyToIndex, m.ColKeyToIndex)
+	}
+
+	if err := m.SetByKey("b", 2, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.Get(1, 1); got != 7 {
+		t.Fatalf("(b, 2) = %d, want 7", got)
+	}
+}
+
+func TestDeleteByKeyReindexes(t *testing.T) {
+
+	m, err := NewKeyedMatrix[int](NewMatrix([][]int{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 8, 9},
+	}), []string{"a", "b", "c"}, []int{10, 20, 30})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.DeleteRowByKey("b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.DeleteColByKey(20); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := m.RowKeyToIndex["b"]; ok || m.RowKeyToIndex["c"] != 1 || len(m.RowKeys) != 2 || m.RowKeys[1] != "c" {
+		t.Fatalf("row keys %v, indices %v", m.RowKeys, m.RowKeyToIndex)
+	}
+
+	if _, ok := m.ColKeyToIndex[20]; ok || m.ColKeyToIndex[30] != 1 || len(m.ColKeys) != 2 || m.ColKeys[1] != 30 {
+		t.Fatalf("column keys %v, indices %v", m.ColKeys, m.ColKeyToIndex)
+	}
+
+	for _, test := range []struct {
+		row  string
+		col  int
+		want int
+	}{
+		{"a", 10, 1}, {"a", 30, 3}, {"c", 10, 7}, {"c", 30, 9},
+	} {
+		if got, ok := m.LookupByKey(test.row, test.col); !ok || got != test.want {
+			t.Fatalf("(%s, %d) = %d, want %d", test.row, test.col, got, test.want)
+		}
+	}
+
+	if err := m.DeleteRowByKey("b"); !errors.Is(err, ErrUnknownRowKey) {
+		t.Fatalf("deleted row key: err = %v, want ErrUnknownRowKey", err)
+	}
+
+	if err := m.DeleteColByKey(20); !errors.Is(err, ErrUnknownColKey) {
+		t.Fatalf("deleted column key: err = %v, want ErrUnknownColKey", err)
+	}
+
+	// a deleted key can be added back as a new last row
+	if err := m.AddRowKey("b"); err != nil || m.RowKeyToIndex["b"] != 2 {
+		t.Fatalf("re-adding b: err %v, index %d", err, m.RowKeyToIndex["b"])
+	}
+}
@@ -0,0 +1,487 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrNotPositiveDefinite = errors.New("matrix is not positive definite")
+	ErrSingular            = errors.New("matrix is singular")
+)
+
+// side of the square blocks Mul works on, so that the blocks of both
+// operands stay in cache
+const blockSize = 64
+
+func Identity[T Numeric](n int) *Matrix[T] {
+
+	m := NewZeroMatrix[T](n, n)
+
+	for i := range n {
+		m.data[i][i] = 1
+	}
+
+	return m
+}
+
+func (m *Matrix[T]) Copy() *Matrix[T] {
+
+	c := NewZeroMatrix[T](m.Rows, m.Cols)
+
+	for i, row := range m.data {
+		copy(c.data[i], row)
+	}
+
+	return c
+}
+
+func (m *Matrix[T]) Add(other *Matrix[T]) (*Matrix[T], error) {
+
+	if m.Rows != other.Rows || m.Cols != other.Cols {
+		return nil, errors.New("matrix dimensions don't equal to other matrix dimensions")
+	}
+
+	result := NewZeroMatrix[T](m.Rows, m.Cols)
+
+	for i, row := range m.data {
+		for j, item := range row {
+			result.data[i][j] = item + other.data[i][j]
+		}
+	}
+
+	return result, nil
+}
+
+func (m *Matrix[T]) Sub(other *Matrix[T]) (*Matrix[T], error) {
+
+	if m.Rows != other.Rows || m.Cols != other.Cols {
+		return nil, errors.New("matrix dimensions don't equal to other matrix dimensions")
+	}
+
+	result := NewZeroMatrix[T](m.Rows, m.Cols)
+
+	for i, row := range m.data {
+		for j, item := range row {
+			result.data[i][j] = item - other.data[i][j]
+		}
+	}
+
+	return result, nil
+}
+
+func (m *Matrix[T]) Scale(factor T) *Matrix[T] {
+
+	result := NewZeroMatrix[T](m.Rows, m.Cols)
+
+	for i, row := range m.data {
+		for j, item := range row {
+			result.data[i][j] = item * factor
+		}
+	}
+
+	return result
+}
+
+func (m *Matrix[T]) MulVec(vector []T) ([]T, error) {
+
+	if len(vector) != m.Cols {
+		return nil, errors.New("vector length doesn't equal to matrix columns amount")
+	}
+
+	result := make([]T, m.Rows)
+
+	for i, row := range m.data {
+
+		var sum T
+
+		for j, item := range row {
+			sum += item * vector[j]
+		}
+		result[i] = sum
+	}
+
+	return result, nil
+}
+
+// Mul multiplies block by block, every block of the result being accumulated
+// from rows of the blocks of other.
+func (m *Matrix[T]) Mul(other *Matrix[T]) (*Matrix[T], error) {
+
+	if m.Cols != other.Rows {
+		return nil, errors.New("matrix columns amount doesn't equal to other matrix rows amount")
+	}
+
+	result := NewZeroMatrix[T](m.Rows, other.Cols)
+
+	for ii := 0; ii < m.Rows; ii += blockSize {
+		for kk := 0; kk < m.Cols; kk += blockSize {
+			for jj := 0; jj < other.Cols; jj += blockSize {
+
+				for i := ii; i < min(ii+blockSize, m.Rows); i++ {
+
+					row := result.data[i][jj:min(jj+blockSize, other.Cols)]
+
+					for k := kk; k < min(kk+blockSize, m.Cols); k++ {
+
+						a := m.data[i][k]
+
+						if a == 0 {
+							continue
+						}
+
+						for j, b := range other.data[k][jj : jj+len(row)] {
+							row[j] += a * b
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Cholesky returns the lower triangular L with a = L·Lᵀ for a symmetric
+// positive definite a, only the lower triangle of a is read.
+func Cholesky(a *Matrix[float64]) (*Matrix[float64], error) {
+
+	if a.Rows != a.Cols {
+		return nil, errors.New("matrix is not square")
+	}
+
+	n := a.Rows
+	l := NewZeroMatrix[float64](n, n)
+
+	for j := range n {
+
+		sum := a.data[j][j]
+
+		for k := range j {
+			sum -= l.data[j][k] * l.data[j][k]
+		}
+
+		if sum <= 0 || math.IsNaN(sum) {
+			return nil, ErrNotPositiveDefinite
+		}
+
+		l.data[j][j] = math.Sqrt(sum)
+
+		for i := j + 1; i < n; i++ {
+
+			sum := a.data[i][j]
+
+			for k := range j {
+				sum -= l.data[i][k] * l.data[j][k]
+			}
+
+			l.data[i][j] = sum / l.data[j][j]
+		}
+	}
+
+	return l, nil
+}
+
+// CholeskySolve solves L·Lᵀ·x = b given the factor L returned by Cholesky.
+func CholeskySolve(l *Matrix[float64], b []float64) ([]float64, error) {
+
+	n := l.Rows
+
+	if len(b) != n {
+		return nil, errors.New("vector length doesn't equal to matrix rows amount")
+	}
+
+	x := make([]float64, n)
+
+	for i := range n {
+
+		sum := b[i]
+
+		for k := range i {
+			sum -= l.data[i][k] * x[k]
+		}
+
+		x[i] = sum / l.data[i][i]
+	}
+
+	for i := n - 1; i >= 0; i-- {
+
+		sum := x[i]
+
+		for k := i + 1; k < n; k++ {
+			sum -= l.data[k][i] * x[k]
+		}
+
+		x[i] = sum / l.data[i][i]
+	}
+
+	return x, nil
+}
+
+// LUDecomposition is P·a = L·U with partial pivoting, L having a unit
+// diagonal.
+type LUDecomposition struct {
+	// L below the diagonal and U on and above it
+	lu    *Matrix[float64]
+	pivot []int
+	sign  float64
+}
+
+func LU(a *Matrix[float64]) (*LUDecomposition, error) {
+
+	if a.Rows != a.Cols {
+		return nil, errors.New("matrix is not square")
+	}
+
+	n := a.Rows
+	lu := a.Copy()
+	pivot := make([]int, n)
+	sign := 1.0
+
+	for i := range n {
+		pivot[i] = i
+	}
+
+	for k := range n {
+
+		p := k
+
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu.data[i][k]) > math.Abs(lu.data[p][k]) {
+				p = i
+			}
+		}
+
+		if p != k {
+			lu.data[p], lu.data[k] = lu.data[k], lu.data[p]
+			pivot[p], pivot[k] = pivot[k], pivot[p]
+			sign = -sign
+		}
+
+		if lu.data[k][k] == 0 {
+			continue
+		}
+
+		for i := k + 1; i < n; i++ {
+
+			lu.data[i][k] /= lu.data[k][k]
+
+			for j := k + 1; j < n; j++ {
+				lu.data[i][j] -= lu.data[i][k] * lu.data[k][j]
+			}
+		}
+	}
+
+	return &LUDecomposition{lu: lu, pivot: pivot, sign: sign}, nil
+}
+
+func (d *LUDecomposition) L() *Matrix[float64] {
+
+	n := d.lu.Rows
+	l := Identity[float64](n)
+
+	for i := range n {
+		copy(l.data[i][:i], d.lu.data[i][:i])
+	}
+
+	return l
+}
+
+func (d *LUDecomposition) U() *Matrix[float64] {
+
+	n := d.lu.Rows
+	u := NewZeroMatrix[float64](n, n)
+
+	for i := range n {
+		copy(u.data[i][i:], d.lu.data[i][i:])
+	}
+
+	return u
+}
+
+// P returns the permutation matrix with P·a = L·U.
+func (d *LUDecomposition) P() *Matrix[float64] {
+
+	n := d.lu.Rows
+	p := NewZeroMatrix[float64](n, n)
+
+	for i, row := range d.pivot {
+		p.data[i][row] = 1
+	}
+
+	return p
+}
+
+func (d *LUDecomposition) Det() float64 {
+
+	det := d.sign
+
+	for i := range d.lu.Rows {
+		det *= d.lu.data[i][i]
+	}
+
+	return det
+}
+
+// Solve solves a·x = b, returning ErrSingular for a singular a.
+func (d *LUDecomposition) Solve(b []float64) ([]float64, error) {
+
+	n := d.lu.Rows
+
+	if len(b) != n {
+		return nil, errors.New("vector length doesn't equal to matrix rows amount")
+	}
+
+	x := make([]float64, n)
+
+	for i := range n {
+
+		sum := b[d.pivot[i]]
+
+		for k := range i {
+			sum -= d.lu.data[i][k] * x[k]
+		}
+
+		x[i] = sum
+	}
+
+	for i := n - 1; i >= 0; i-- {
+
+		if d.lu.data[i][i] == 0 {
+			return nil, ErrSingular
+		}
+
+		sum := x[i]
+
+		for k := i + 1; k < n; k++ {
+			sum -= d.lu.data[i][k] * x[k]
+		}
+
+		x[i] = sum / d.lu.data[i][i]
+	}
+
+	return x, nil
+}
+
+// Inverse returns ErrSingular for a singular a.
+func Inverse(a *Matrix[float64]) (*Matrix[float64], error) {
+
+	d, err := LU(a)
+
+	if err != nil {
+		return nil, err
+	}
+
+	n := a.Rows
+	inverse := NewZeroMatrix[float64](n, n)
+	e := make([]float64, n)
+
+	for j := range n {
+
+		clear(e)
+		e[j] = 1
+
+		col, err := d.Solve(e)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range n {
+			inverse.data[i][j] = col[i]
+		}
+	}
+
+	return inverse, nil
+}
+
+// QR returns the thin decomposition a = Q·R of a with at least as many rows
+// as columns computed with Householder reflections, Q having orthonormal
+// columns and R being upper triangular.
+func QR(a *Matrix[float64]) (*Matrix[float64], *Matrix[float64], error) {
+
+	m, n := a.Rows, a.Cols
+
+	if m < n {
+		return nil, nil, errors.New("matrix has fewer rows than columns")
+	}
+
+	r := a.Copy()
+	// the Householder vector of column k is stored in vs[k][k:]
+	vs := make([][]float64, n)
+
+	for k := range n {
+
+		norm := 0.0
+
+		for i := k; i < m; i++ {
+			norm += r.data[i][k] * r.data[i][k]
+		}
+
+		norm = math.Sqrt(norm)
+
+		v := make([]float64, m)
+
+		if norm == 0 {
+			vs[k] = v
+			continue
+		}
+
+		alpha := -math.Copysign(norm, r.data[k][k])
+
+		for i := k; i < m; i++ {
+			v[i] = r.data[i][k]
+		}
+		v[k] -= alpha
+
+		applyHouseholder(r, v, k)
+		vs[k] = v
+
+		r.data[k][k] = alpha
+
+		for i := k + 1; i < m; i++ {
+			r.data[i][k] = 0
+		}
+	}
+
+	q := NewZeroMatrix[float64](m, n)
+
+	for i := range n {
+		q.data[i][i] = 1
+	}
+
+	for k := n - 1; k >= 0; k-- {
+		applyHouseholder(q, vs[k], k)
+	}
+
+	return q, &Matrix[float64]{data: r.data[:n], Rows: n, Cols: n}, nil
+}
+
+// applyHouseholder applies the reflection I - 2vvᵀ/vᵀv to the rows from k of m.
+func applyHouseholder(m *Matrix[float64], v []float64, k int) {
+
+	vv := 0.0
+
+	for i := k; i < m.Rows; i++ {
+		vv += v[i] * v[i]
+	}
+
+	if vv == 0 {
+		return
+	}
+
+	for j := range m.Cols {
+
+		dot := 0.0
+
+		for i := k; i < m.Rows; i++ {
+			dot += v[i] * m.data[i][j]
+		}
+
+		factor := 2 * dot / vv
+
+		for i := k; i < m.Rows; i++ {
+			m.data[i][j] -= factor * v[i]
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func assertClose(t *testing.T, name string, want *Matrix[float64], got *Matrix[float64], tolerance float64) {
+
+	t.Helper()
+
+	if want.Rows != got.Rows || want.Cols != got.Cols {
+		t.Fatalf("%s: dims %dx%d, want %dx%d", name, got.Rows, got.Cols, want.Rows, want.Cols)
+	}
+
+	for i := range want.Rows {
+		for j := range want.Cols {
+
+			if math.Abs(want.data[i][j]-got.data[i][j]) > tolerance {
+				t.Fatalf("%s: (%d, %d) = %v, want %v", name, i, j, got.data[i][j], want.data[i][j])
+			}
+		}
+	}
+}
+
+func naiveMul[T Numeric](a *Matrix[T], b *Matrix[T]) *Matrix[T] {
+
+	result := NewZeroMatrix[T](a.Rows, b.Cols)
+
+	for i := range a.Rows {
+		for j := range b.Cols {
+			for k := range a.Cols {
+				result.data[i][j] += a.data[i][k] * b.data[k][j]
+			}
+		}
+	}
+
+	return result
+}
+
+func transpose(m *Matrix[float64]) *Matrix[float64] {
+
+	result := NewZeroMatrix[float64](m.Cols, m.Rows)
+
+	for i := range m.Rows {
+		for j := range m.Cols {
+			result.data[j][i] = m.data[i][j]
+		}
+	}
+
+	return result
+}
+
+func TestCholesky(t *testing.T) {
+
+	a := NewMatrix([][]float64{
+		{4, 12, -16},
+		{12, 37, -43},
+		{-16, -43, 98},
+	})
+
+	l, err := Cholesky(a)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClose(t, "L", NewMatrix([][]float64{
+		{2, 0, 0},
+		{6, 1, 0},
+		{-8, 5, 3},
+	}), l, 1e-12)
+
+	// a·x = b for x = (1, 2, 3)
+	x, err := CholeskySolve(l, []float64{-20, -43, 192})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []float64{1, 2, 3} {
+		if math.Abs(x[i]-want) > 1e-9 {
+			t.Fatalf("x = %v, want (1, 2, 3)", x)
+		}
+	}
+
+	if _, err := Cholesky(NewMatrix([][]float64{{1, 2}, {2, 1}})); !errors.Is(err, ErrNotPositiveDefinite) {
+		t.Fatalf("indefinite matrix: err = %v, want ErrNotPositiveDefinite", err)
+	}
+}
+
+func TestLU(t *testing.T) {
+
+	a := NewMatrix([][]float64{
+		{2, 1, 1},
+		{4, -6, 0},
+		{-2, 7, 2},
+	})
+
+	d, err := LU(a)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pa := naiveMul(d.P(), a)
+	assertClose(t, "P·A = L·U", pa, naiveMul(d.L(), d.U()), 1e-12)
+
+	if det := d.Det(); math.Abs(det-(-16)) > 1e-12 {
+		t.Fatalf("det = %v, want -16", det)
+	}
+
+	// a·x = b for x = (1, -1, 2)
+	x, err := d.Solve([]float64{3, 10, -5})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []float64{1, -1, 2} {
+		if math.Abs(x[i]-want) > 1e-12 {
+			t.Fatalf("x = %v, want (1, -1, 2)", x)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+
+	inverse, err := Inverse(NewMatrix([][]float64{{4, 7}, {2, 6}}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClose(t, "inverse", NewMatrix([][]float64{{0.6, -0.7}, {-0.2, 0.4}}), inverse, 1e-12)
+
+	if _, err := Inverse(NewMatrix([][]float64{{1, 2}, {2, 4}})); !errors.Is(err, ErrSingular) {
+		t.Fatalf("singular matrix: err = %v, want ErrSingular", err)
+	}
+
+	// the second row is twice the first, so elimination leaves an exact 0
+	d, err := LU(NewMatrix([][]float64{{1, 2, 3}, {2, 4, 6}, {1, 0, 1}}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Solve([]float64{1, 2, 3}); !errors.Is(err, ErrSingular) {
+		t.Fatalf("singular system: err = %v, want ErrSingular", err)
+	}
+}
+
+func TestQR(t *testing.T) {
+
+	a := NewMatrix([][]float64{
+		{12, -51, 4},
+		{6, 167, -68},
+		{-4, 24, -41},
+	})
+
+	q, r, err := QR(a)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// R is unique up to the signs of its rows
+	for i, want := range []float64{14, 175, 35} {
+		if math.Abs(math.Abs(r.data[i][i])-want) > 1e-9 {
+			t.Fatalf("R diagonal %v, want ±(14, 175, 35)", r)
+		}
+	}
+
+	for i := range r.Rows {
+		for j := range i {
+			if r.data[i][j] != 0 {
+				t.Fatalf("R is not upper triangular:\n%v", r)
+			}
+		}
+	}
+
+	assertClose(t, "Q·R", a, naiveMul(q, r), 1e-9)
+	assertClose(t, "Qᵀ·Q", Identity[float64](3), naiveMul(transpose(q), q), 1e-12)
+
+	// thin decomposition of a tall matrix
+	rng := rand.New(rand.NewSource(1))
+	tall := NewZeroMatrix[float64](10, 4)
+
+	for i := range tall.Rows {
+		for j := range tall.Cols {
+			tall.data[i][j] = rng.NormFloat64()
+		}
+	}
+
+	q, r, err = QR(tall)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Rows != 10 || q.Cols != 4 || r.Rows != 4 || r.Cols != 4 {
+		t.Fatalf("Q is %dx%d and R %dx%d, want 10x4 and 4x4", q.Rows, q.Cols, r.Rows, r.Cols)
+	}
+
+	assertClose(t, "thin Q·R", tall, naiveMul(q, r), 1e-12)
+	assertClose(t, "thin Qᵀ·Q", Identity[float64](4), naiveMul(transpose(q), q), 1e-12)
+
+	if _, _, err := QR(NewZeroMatrix[float64](2, 3)); err == nil {
+		t.Fatal("expected an error for a wide matrix")
+	}
+}
+
+func TestMulBlocked(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(2))
+
+	// larger than a block in every dimension and not a multiple of it
+	a := NewZeroMatrix[int](blockSize+7, 2*blockSize+3)
+	b := NewZeroMatrix[int](2*blockSize+3, blockSize+11)
+
+	for _, m := range []*Matrix[int]{a, b} {
+		for i := range m.Rows {
+			for j := range m.Cols {
+				m.data[i][j] = rng.Intn(21) - 10
+			}
+		}
+	}
+
+	product, err := a.Mul(b)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := naiveMul(a, b)
+
+	for i := range want.Rows {
+		for j := range want.Cols {
+			if product.data[i][j] != want.data[i][j] {
+				t.Fatalf("(%d, %d) = %d, want %d", i, j, product.data[i][j], want.data[i][j])
+			}
+		}
+	}
+}
+
+func TestShapeMismatch(t *testing.T) {
+
+	a := NewZeroMatrix[float64](2, 3)
+	b := NewZeroMatrix[float64](2, 3)
+
+	if _, err := a.Mul(b); err == nil {
+		t.Fatal("Mul: expected an error for 2x3 · 2x3")
+	}
+
+	if _, err := a.Add(NewZeroMatrix[float64](3, 2)); err == nil {
+		t.Fatal("Add: expected an error for different dims")
+	}
+
+	if _, err := a.Sub(NewZeroMatrix[float64](3, 2)); err == nil {
+		t.Fatal("Sub: expected an error for different dims")
+	}
+
+	if _, err := a.MulVec([]float64{1, 2}); err == nil {
+		t.Fatal("MulVec: expected an error for a vector of length 2")
+	}
+
+	if _, err := Cholesky(a); err == nil {
+		t.Fatal("Cholesky: expected an error for a non-square matrix")
+	}
+
+	if _, err := LU(a); err == nil {
+		t.Fatal("LU: expected an error for a non-square matrix")
+	}
+}
@@ -0,0 +1,289 @@
+package matrix
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type MMField string
+
+const (
+	MMReal    MMField = "real"
+	MMInteger MMField = "integer"
+	MMPattern MMField = "pattern"
+)
+
+type MMSymmetry string
+
+const (
+	MMGeneral   MMSymmetry = "general"
+	MMSymmetric MMSymmetry = "symmetric"
+)
+
+type MMHeader struct {
+	Field    MMField
+	Symmetry MMSymmetry
+}
+
+const mmBanner = "%%MatrixMarket"
+
+func parseMMHeader(line string) (MMHeader, error) {
+
+	fields := strings.Fields(strings.ToLower(line))
+
+	if len(fields) != 5 || fields[0] != strings.ToLower(mmBanner) {
+		return MMHeader{}, errors.New("missing %%MatrixMarket banner")
+	}
+
+	if fields[1] != "matrix" || fields[2] != "coordinate" {
+		return MMHeader{}, fmt.Errorf("unsupported object %q %q, only matrix coordinate is supported", fields[1], fields[2])
+	}
+
+	header := MMHeader{Field: MMField(fields[3]), Symmetry: MMSymmetry(fields[4])}
+
+	switch header.Field {
+	case MMReal, MMInteger, MMPattern:
+	default:
+		return MMHeader{}, fmt.Errorf("unsupported field %q", fields[3])
+	}
+
+	switch header.Symmetry {
+	case MMGeneral, MMSymmetric:
+	default:
+		return MMHeader{}, fmt.Errorf("unsupported symmetry %q", fields[4])
+	}
+
+	return header, nil
+}
+
+// parseMMValue rejects real values with a fractional part when T is an
+// integer type instead of truncating them.
+func parseMMValue[T Numeric](field MMField, s string) (T, error) {
+
+	switch field {
+	case MMInteger:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return T(v), err
+
+	default:
+		v, err := strconv.ParseFloat(s, 64)
+
+		if err == nil && mmField[T]() == MMInteger && v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return T(v), err
+	}
+}
+
+// ReadMatrixMarket reads a matrix in Matrix Market coordinate format. For
+// symmetric matrices the entries above the diagonal are restored, and pattern
+// entries get the value 1.
+func ReadMatrixMarket[T Numeric](r io.Reader) (*CoordinateList[T], MMHeader, error) {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line_n := 0
+
+	if !scanner.Scan() {
+
+		if err := scanner.Err(); err != nil {
+			return nil, MMHeader{}, err
+		}
+		return nil, MMHeader{}, errors.New("empty Matrix Market input")
+	}
+	line_n++
+
+	header, err := parseMMHeader(scanner.Text())
+
+	if err != nil {
+		return nil, MMHeader{}, fmt.Errorf("line %d: %w", line_n, err)
+	}
+
+	var cl *CoordinateList[T]
+	nnz := -1
+
+	for scanner.Scan() {
+
+		line_n++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "%") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		if cl == nil {
+
+			if len(fields) != 3 {
+				return nil, header, fmt.Errorf("line %d: expected size line \"rows cols entries\"", line_n)
+			}
+
+			sizes := make([]int, 3)
+
+			for i, f := range fields {
+
+				if sizes[i], err = strconv.Atoi(f); err != nil || sizes[i] < 0 {
+					return nil, header, fmt.Errorf("line %d: invalid size %q", line_n, f)
+				}
+			}
+
+			if header.Symmetry == MMSymmetric && sizes[0] != sizes[1] {
+				return nil, header, fmt.Errorf("line %d: symmetric matrix must be square", line_n)
+			}
+
+			cl = NewCoordinateList[T](sizes[0], sizes[1])
+			nnz = sizes[2]
+			continue
+		}
+
+		expected := 3
+		if header.Field == MMPattern {
+			expected = 2
+		}
+
+		if len(fields) != expected {
+			return nil, header, fmt.Errorf("line %d: expected %d fields, got %d", line_n, expected, len(fields))
+		}
+
+		row_n, err_row := strconv.Atoi(fields[0])
+		col_n, err_col := strconv.Atoi(fields[1])
+
+		if err_row != nil || err_col != nil || row_n < 1 || row_n > cl.Rows || col_n < 1 || col_n > cl.Cols {
+			return nil, header, fmt.Errorf("line %d: invalid position (%s, %s)", line_n, fields[0], fields[1])
+		}
+
+		val := T(1)
+
+		if header.Field != MMPattern {
+
+			if val, err = parseMMValue[T](header.Field, fields[2]); err != nil {
+				return nil, header, fmt.Errorf("line %d: invalid value %q: %w", line_n, fields[2], err)
+			}
+		}
+
+		cl.Append(row_n-1, col_n-1, val)
+
+		if header.Symmetry == MMSymmetric && row_n != col_n {
+			cl.Append(col_n-1, row_n-1, val)
+		}
+
+		nnz--
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, header, err
+	}
+
+	if cl == nil {
+		return nil, header, errors.New("missing size line")
+	}
+
+	if nnz != 0 {
+		return nil, header, errors.New("entries amount doesn't equal to the one declared in the size line")
+	}
+
+	return cl, header, nil
+}
+
+func ReadMatrixMarketCSR[T Numeric](r io.Reader) (*CSR[T], MMHeader, error) {
+
+	cl, header, err := ReadMatrixMarket[T](r)
+
+	if err != nil {
+		return nil, header, err
+	}
+
+	csr := cl.ToCSR()
+
+	return &csr, header, nil
+}
+
+func mmField[T Numeric]() MMField {
+
+	var zero T
+
+	switch any(zero).(type) {
+	case float32, float64:
+		return MMReal
+	default:
+		return MMInteger
+	}
+}
+
+// sameValue compares a and b treating NaN as equal to NaN, e.g. the
+// similarities of objects without ratings.
+func sameValue[T Numeric](a T, b T) bool {
+	return a == b || (a != a && b != b)
+}
+
+// WriteMatrixMarket writes the non-zero cells of m in Matrix Market coordinate
+// format. An empty header field is derived from T, and a symmetric header
+// writes only the lower triangle after checking that m is symmetric, NaN
+// cells being written as NaN. An integer field rejects fractional values.
+func WriteMatrixMarket[T Numeric](w io.Writer, m IMatrix[T], header MMHeader) error {
+
+	if header.Field == "" {
+		header.Field = mmField[T]()
+	}
+
+	if header.Symmetry == "" {
+		header.Symmetry = MMGeneral
+	}
+
+	rows, cols := m.Dims()
+
+	if header.Symmetry == MMSymmetric && rows != cols {
+		return errors.New("symmetric matrix must be square")
+	}
+
+	var sb strings.Builder
+	nnz := 0
+
+	for row_n := range rows {
+
+		indices, values := m.GetSparseRow(row_n)
+
+		for k, col_n := range indices {
+
+			if header.Symmetry == MMSymmetric {
+
+				if !sameValue(m.Get(col_n, row_n), values[k]) {
+					return fmt.Errorf("matrix is not symmetric at (%d, %d)", row_n, col_n)
+				}
+
+				if col_n > row_n {
+					continue
+				}
+			}
+
+			switch header.Field {
+			case MMPattern:
+				fmt.Fprintf(&sb, "%d %d\n", row_n+1, col_n+1)
+			case MMInteger:
+
+				if float64(values[k]) != math.Trunc(float64(values[k])) {
+					return fmt.Errorf("value %v at (%d, %d) is not an integer", values[k], row_n, col_n)
+				}
+				fmt.Fprintf(&sb, "%d %d %d\n", row_n+1, col_n+1, int64(values[k]))
+			default:
+				fmt.Fprintf(&sb, "%d %d %s\n", row_n+1, col_n+1, strconv.FormatFloat(float64(values[k]), 'g', -1, 64))
+			}
+			nnz++
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "%s matrix coordinate %s %s\n", mmBanner, header.Field, header.Symmetry)
+	fmt.Fprintf(bw, "%d %d %d\n", rows, cols, nnz)
+	bw.WriteString(sb.String())
+
+	return bw.Flush()
+}
@@ -0,0 +1,184 @@
+package matrix
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// similarity matrices have NaN cells for objects without ratings
+func TestWriteMatrixMarketSymmetricNaN(t *testing.T) {
+
+	nan := math.NaN()
+
+	m := NewMatrix([][]float64{
+		{1, 0.5, nan},
+		{0.5, 1, nan},
+		{nan, nan, nan},
+	})
+
+	var sb strings.Builder
+
+	if err := WriteMatrixMarket[float64](&sb, m, MMHeader{Symmetry: MMSymmetric}); err != nil {
+		t.Fatal(err)
+	}
+
+	cl, _, err := ReadMatrixMarket[float64](strings.NewReader(sb.String()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := cl.ToDense()
+
+	for i := range m.Rows {
+		for j := range m.Cols {
+
+			if !sameValue(m.Get(i, j), read.Get(i, j)) {
+				t.Fatalf("(%d, %d) = %v, want %v", i, j, read.Get(i, j), m.Get(i, j))
+			}
+		}
+	}
+}
+
+func TestWriteMatrixMarketNotSymmetric(t *testing.T) {
+
+	m := NewMatrix([][]float64{
+		{1, 2},
+		{math.NaN(), 1},
+	})
+
+	var sb strings.Builder
+
+	if err := WriteMatrixMarket[float64](&sb, m, MMHeader{Symmetry: MMSymmetric}); err == nil {
+		t.Fatal("expected an error for a non-symmetric matrix")
+	}
+}
+
+func TestReadMatrixMarketGeneral(t *testing.T) {
+
+	input := `%%MatrixMarket matrix coordinate real general
+% a comment
+
+3 4 4
+1 1 1.5
+1 4 -2
+3 2 0.25
+2 3 7
+`
+
+	cl, header, err := ReadMatrixMarket[float64](strings.NewReader(input))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header != (MMHeader{Field: MMReal, Symmetry: MMGeneral}) {
+		t.Fatalf("header %v", header)
+	}
+
+	assertEqualDense(t, "general", NewMatrix([][]float64{
+		{1.5, 0, 0, -2},
+		{0, 0, 7, 0},
+		{0, 0.25, 0, 0},
+	}), cl.ToDense())
+
+	// written back and read again
+	var sb strings.Builder
+
+	if err := WriteMatrixMarket[float64](&sb, cl, MMHeader{}); err != nil {
+		t.Fatal(err)
+	}
+
+	csr, _, err := ReadMatrixMarketCSR[float64](strings.NewReader(sb.String()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualDense(t, "round trip", cl.ToDense(), csr.ToDense())
+}
+
+func TestReadMatrixMarketPattern(t *testing.T) {
+
+	input := `%%MatrixMarket matrix coordinate pattern symmetric
+3 3 3
+1 1
+3 1
+3 2
+`
+
+	cl, _, err := ReadMatrixMarket[int](strings.NewReader(input))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqualDense(t, "pattern", NewMatrix([][]int{
+		{1, 0, 1},
+		{0, 0, 1},
+		{1, 1, 0},
+	}), cl.ToDense())
+
+	var sb strings.Builder
+
+	if err := WriteMatrixMarket[int](&sb, cl, MMHeader{Field: MMPattern, Symmetry: MMSymmetric}); err != nil {
+		t.Fatal(err)
+	}
+
+	if sb.String() != input {
+		t.Fatalf("pattern output:\n%s\nwant:\n%s", sb.String(), input)
+	}
+}
+
+func TestReadMatrixMarketInteger(t *testing.T) {
+
+	input := `%%MatrixMarket matrix coordinate integer general
+2 2 2
+1 2 -3
+2 1 4
+`
+
+	ints, _, err := ReadMatrixMarket[int](strings.NewReader(input))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualDense(t, "integer into int", NewMatrix([][]int{{0, -3}, {4, 0}}), ints.ToDense())
+
+	floats, _, err := ReadMatrixMarket[float64](strings.NewReader(input))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualDense(t, "integer into float64", NewMatrix([][]float64{{0, -3}, {4, 0}}), floats.ToDense())
+
+	if _, _, err := ReadMatrixMarket[int](strings.NewReader(strings.Replace(input, "-3", "2.5", 1))); err == nil {
+		t.Fatal("expected an error for a real value in an integer file")
+	}
+}
+
+func TestReadMatrixMarketRealIntoInt(t *testing.T) {
+
+	input := `%%MatrixMarket matrix coordinate real general
+1 2 2
+1 1 3.0
+1 2 2.5
+`
+
+	if _, _, err := ReadMatrixMarket[int](strings.NewReader(input)); err == nil {
+		t.Fatal("expected an error for 2.5 read into int")
+	}
+
+	cl, _, err := ReadMatrixMarket[int](strings.NewReader(strings.Replace(input, "2.5", "-2", 1)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualDense(t, "integral reals into int", NewMatrix([][]int{{3, -2}}), cl.ToDense())
+
+	var sb strings.Builder
+
+	if err := WriteMatrixMarket[float64](&sb, NewMatrix([][]float64{{0.5}}), MMHeader{Field: MMInteger}); err == nil {
+		t.Fatal("expected an error for writing 0.5 as an integer")
+	}
+}
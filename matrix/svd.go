@@ -0,0 +1,202 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SVD holds the truncated decomposition a ≈ U·diag(S)·Vᵀ, the singular values
+// in S being in decreasing order.
+type SVD struct {
+	U *Matrix[float64]
+	S []float64
+	V *Matrix[float64]
+}
+
+// TruncatedSVD computes the k largest singular triplets of a with the
+// randomized algorithm of Halko, Martinsson and Tropp: a is projected on
+// k + oversampling random vectors, the range found is refined with
+// power_iterations passes over a and aᵀ, and the small projected matrix is
+// decomposed exactly.
+func TruncatedSVD(a *CSR[float64], k int, oversampling int, power_iterations int, rng *rand.Rand) (*SVD, error) {
+
+	if k <= 0 || k > min(a.Rows, a.Cols) {
+		return nil, errors.New("rank is out of range")
+	}
+
+	l := min(k+max(oversampling, 0), a.Rows, a.Cols)
+	transposed := a.Transpose()
+
+	omega := NewZeroMatrix[float64](a.Cols, l)
+
+	for i := range a.Cols {
+		for j := range l {
+			omega.data[i][j] = rng.NormFloat64()
+		}
+	}
+
+	y, err := a.MulDense(omega)
+
+	if err != nil {
+		return nil, err
+	}
+
+	q, _, err := QR(y)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for range power_iterations {
+
+		z, _ := transposed.MulDense(q)
+
+		if q, _, err = QR(z); err != nil {
+			return nil, err
+		}
+
+		y, _ = a.MulDense(q)
+
+		if q, _, err = QR(y); err != nil {
+			return nil, err
+		}
+	}
+
+	// bᵀ = aᵀ·q is n×l, so its decomposition w·diag(s)·vᵀ gives
+	// a ≈ q·b = (q·v)·diag(s)·wᵀ
+	bt, _ := transposed.MulDense(q)
+	w, s, v := jacobiSVD(bt)
+
+	u, _ := q.Mul(v)
+
+	return &SVD{
+		U: columns(u, k),
+		S: s[:k],
+		V: columns(w, k),
+	}, nil
+}
+
+// columns returns the first k columns of m.
+func columns(m *Matrix[float64], k int) *Matrix[float64] {
+
+	result := NewZeroMatrix[float64](m.Rows, k)
+
+	for i := range m.Rows {
+		copy(result.data[i], m.data[i][:k])
+	}
+
+	return result
+}
+
+// jacobiSVD decomposes m with at least as many rows as columns into
+// u·diag(s)·vᵀ by one-sided Jacobi rotations, which orthogonalize the
+// columns of m.
+func jacobiSVD(m *Matrix[float64]) (*Matrix[float64], []float64, *Matrix[float64]) {
+
+	rows, cols := m.Rows, m.Cols
+	u := m.Copy()
+	v := Identity[float64](cols)
+
+	const sweeps = 60
+
+	for range sweeps {
+
+		rotated := false
+
+		for p := range cols {
+			for q := p + 1; q < cols; q++ {
+
+				alpha, beta, gamma := 0.0, 0.0, 0.0
+
+				for i := range rows {
+					alpha += u.data[i][p] * u.data[i][p]
+					beta += u.data[i][q] * u.data[i][q]
+					gamma += u.data[i][p] * u.data[i][q]
+				}
+
+				if gamma == 0 || math.Abs(gamma) <= 1e-15*math.Sqrt(alpha*beta) {
+					continue
+				}
+
+				rotated = true
+
+				zeta := (beta - alpha) / (2 * gamma)
+				t := math.Copysign(1, zeta) / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+
+				rotateColumns(u, p, q, c, s)
+				rotateColumns(v, p, q, c, s)
+			}
+		}
+
+		if !rotated {
+			break
+		}
+	}
+
+	s := make([]float64, cols)
+
+	for j := range cols {
+
+		norm := 0.0
+
+		for i := range rows {
+			norm += u.data[i][j] * u.data[i][j]
+		}
+
+		s[j] = math.Sqrt(norm)
+
+		if s[j] != 0 {
+			for i := range rows {
+				u.data[i][j] /= s[j]
+			}
+		}
+	}
+
+	order := make([]int, cols)
+
+	for j := range cols {
+		order[j] = j
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return s[order[i]] > s[order[j]]
+	})
+
+	return permuteColumns(u, order), permute(s, order), permuteColumns(v, order)
+}
+
+func rotateColumns(m *Matrix[float64], p int, q int, c float64, s float64) {
+
+	for i := range m.Rows {
+
+		mp, mq := m.data[i][p], m.data[i][q]
+		m.data[i][p] = c*mp - s*mq
+		m.data[i][q] = s*mp + c*mq
+	}
+}
+
+func permuteColumns(m *Matrix[float64], order []int) *Matrix[float64] {
+
+	result := NewZeroMatrix[float64](m.Rows, m.Cols)
+
+	for i := range m.Rows {
+		result.data[i] = permute(m.data[i], order)
+	}
+
+	return result
+}
+
+func permute(values []float64, order []int) []float64 {
+
+	result := make([]float64, len(order))
+
+	for j, from := range order {
+		result[j] = values[from]
+	}
+
+	return result
+}
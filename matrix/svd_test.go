@@ -0,0 +1,116 @@
+package matrix
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomNormal(rng *rand.Rand, rows int, cols int) *Matrix[float64] {
+
+	m := NewZeroMatrix[float64](rows, cols)
+
+	for i := range rows {
+		for j := range cols {
+			m.data[i][j] = rng.NormFloat64()
+		}
+	}
+
+	return m
+}
+
+// reconstruct returns U·diag(S)·Vᵀ.
+func reconstruct(svd *SVD) *Matrix[float64] {
+
+	us := NewZeroMatrix[float64](svd.U.Rows, svd.U.Cols)
+
+	for i := range us.Rows {
+		for j := range us.Cols {
+			us.data[i][j] = svd.U.data[i][j] * svd.S[j]
+		}
+	}
+
+	return naiveMul(us, transpose(svd.V))
+}
+
+func assertValidSVD(t *testing.T, svd *SVD, rows int, cols int, k int) {
+
+	t.Helper()
+
+	if svd.U.Rows != rows || svd.U.Cols != k || svd.V.Rows != cols || svd.V.Cols != k || len(svd.S) != k {
+		t.Fatalf("U is %dx%d, V %dx%d and %d singular values, want %dx%d, %dx%d and %d", svd.U.Rows, svd.U.Cols, svd.V.Rows, svd.V.Cols, len(svd.S), rows, k, cols, k, k)
+	}
+
+	for i := 1; i < k; i++ {
+		if svd.S[i] > svd.S[i-1] {
+			t.Fatalf("singular values %v aren't decreasing", svd.S)
+		}
+	}
+
+	assertClose(t, "Uᵀ·U", Identity[float64](k), naiveMul(transpose(svd.U), svd.U), 1e-12)
+	assertClose(t, "Vᵀ·V", Identity[float64](k), naiveMul(transpose(svd.V), svd.V), 1e-12)
+}
+
+func TestTruncatedSVDRecoversLowRank(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+
+	// a rank 3 product is found exactly by 3 triplets
+	a := naiveMul(randomNormal(rng, 20, 3), randomNormal(rng, 3, 15))
+	csr := a.ToCSR()
+
+	svd, err := TruncatedSVD(&csr, 3, 5, 2, rng)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertValidSVD(t, svd, 20, 15, 3)
+	assertClose(t, "U·S·Vᵀ", a, reconstruct(svd), 1e-13)
+
+	// the rank 2 truncation leaves out the smallest triplet
+	truncated, err := TruncatedSVD(&csr, 2, 5, 2, rng)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertValidSVD(t, truncated, 20, 15, 2)
+
+	for i, s := range truncated.S {
+		if math.Abs(s-svd.S[i]) > 1e-10 {
+			t.Fatalf("rank 2 singular values %v, want the first of %v", truncated.S, svd.S)
+		}
+	}
+}
+
+func TestTruncatedSVDRank(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(2))
+
+	// k = min(dims) decomposes a full rank matrix, the oversampling being
+	// clipped
+	for _, dims := range [][2]int{{6, 4}, {4, 6}, {5, 5}} {
+
+		a := randomNormal(rng, dims[0], dims[1])
+		csr := a.ToCSR()
+		k := min(dims[0], dims[1])
+
+		svd, err := TruncatedSVD(&csr, k, 10, 1, rng)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertValidSVD(t, svd, dims[0], dims[1], k)
+		assertClose(t, "full U·S·Vᵀ", a, reconstruct(svd), 1e-12)
+	}
+
+	csr := randomNormal(rng, 6, 4).ToCSR()
+
+	for _, k := range []int{0, -1, 5} {
+		if _, err := TruncatedSVD(&csr, k, 10, 1, rng); err == nil {
+			t.Fatalf("expected an error for rank %d of a 6x4 matrix", k)
+		}
+	}
+}
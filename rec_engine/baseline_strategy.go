@@ -0,0 +1,234 @@
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+type BaselineMethod int
+
+const (
+	// alternating closed-form updates of the item and the user biases
+	BaselineALS BaselineMethod = iota
+	// stochastic gradient descent on the regularized squared error
+	BaselineSGD
+)
+
+type BaselineConfig struct {
+	Method BaselineMethod
+	Epochs int
+
+	// ALS damping terms: a bias of a user with n ratings is shrunk towards 0
+	// by n/(n + UserDamping), the same for items
+	UserDamping float64
+	ItemDamping float64
+
+	// SGD step and L2 penalty of the biases
+	LearningRate   float64
+	Regularization float64
+
+	// seed of the order SGD visits the ratings in
+	Seed int64
+}
+
+type BaselineOption func(*BaselineConfig)
+
+func WithBaselineMethod(method BaselineMethod) BaselineOption {
+	return func(c *BaselineConfig) { c.Method = method }
+}
+
+func WithBaselineEpochs(epochs int) BaselineOption {
+	return func(c *BaselineConfig) { c.Epochs = epochs }
+}
+
+func WithDamping(user_damping float64, item_damping float64) BaselineOption {
+	return func(c *BaselineConfig) {
+		c.UserDamping = user_damping
+		c.ItemDamping = item_damping
+	}
+}
+
+func WithBaselineLearningRate(rate float64) BaselineOption {
+	return func(c *BaselineConfig) { c.LearningRate = rate }
+}
+
+func WithBaselineRegularization(lambda float64) BaselineOption {
+	return func(c *BaselineConfig) { c.Regularization = lambda }
+}
+
+func WithBaselineSeed(seed int64) BaselineOption {
+	return func(c *BaselineConfig) { c.Seed = seed }
+}
+
+// newBaselineConfig defaults to 10 epochs of ALS with damping 15 for users and
+// 10 for items, SGD using learning rate 0.005, regularization 0.02 and seed 1.
+func newBaselineConfig(options []BaselineOption) BaselineConfig {
+
+	config := BaselineConfig{
+		Method:         BaselineALS,
+		Epochs:         10,
+		UserDamping:    15,
+		ItemDamping:    10,
+		LearningRate:   0.005,
+		Regularization: 0.02,
+		Seed:           1,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
+
+// baselineModel is b_ui = μ + b_u + b_i, the biases indexed by the column and
+// row indices of the preference matrix.
+type baselineModel struct {
+	globalMean float64
+	userBias   []float64
+	itemBias   []float64
+}
+
+func (m *baselineModel) predict(user_n int, item_n int) float64 {
+	return m.globalMean + m.userBias[user_n] + m.itemBias[item_n]
+}
+
+func (m *baselineModel) predictByKey(preferenceMatrix *KeyedMatrix[float64, Item, User], user User, item Item) float64 {
+	return m.predict(preferenceMatrix.ColKeyToIndex[user], preferenceMatrix.RowKeyToIndex[item])
+}
+
+func fitBaseline(config BaselineConfig, preferenceMatrix *KeyedMatrix[float64, Item, User]) (*baselineModel, error) {
+
+	items_n, users_n := preferenceMatrix.RowsN(), preferenceMatrix.ColsN()
+
+	ratings := make([]observedRating, 0, preferenceMatrix.Nnz())
+	sum := 0.0
+
+	for item_n := range items_n {
+
+		indices, values := preferenceMatrix.GetSparseRow(item_n)
+
+		for k, user_n := range indices {
+
+			if values[k] != 0 {
+				ratings = append(ratings, observedRating{item_n: item_n, user_n: user_n, value: values[k]})
+				sum += values[k]
+			}
+		}
+	}
+
+	model := &baselineModel{
+		userBias: make([]float64, users_n),
+		itemBias: make([]float64, items_n),
+	}
+
+	if len(ratings) > 0 {
+		model.globalMean = sum / float64(len(ratings))
+	}
+
+	switch config.Method {
+	case BaselineALS:
+
+		residuals := make([]float64, max(users_n, items_n))
+		counts := make([]int, max(users_n, items_n))
+
+		for range config.Epochs {
+
+			clear(residuals)
+			clear(counts)
+
+			for _, r := range ratings {
+				residuals[r.item_n] += r.value - model.globalMean - model.userBias[r.user_n]
+				counts[r.item_n]++
+			}
+
+			for item_n := range items_n {
+				model.itemBias[item_n] = residuals[item_n] / (config.ItemDamping + float64(counts[item_n]))
+			}
+
+			clear(residuals)
+			clear(counts)
+
+			for _, r := range ratings {
+				residuals[r.user_n] += r.value - model.globalMean - model.itemBias[r.item_n]
+				counts[r.user_n]++
+			}
+
+			for user_n := range users_n {
+				model.userBias[user_n] = residuals[user_n] / (config.UserDamping + float64(counts[user_n]))
+			}
+		}
+
+	case BaselineSGD:
+
+		rng := rand.New(rand.NewSource(config.Seed))
+		lr, reg := config.LearningRate, config.Regularization
+
+		for range config.Epochs {
+
+			rng.Shuffle(len(ratings), func(i, j int) {
+				ratings[i], ratings[j] = ratings[j], ratings[i]
+			})
+
+			for _, r := range ratings {
+
+				residual := r.value - model.predict(r.user_n, r.item_n)
+
+				model.userBias[r.user_n] += lr * (residual - reg*model.userBias[r.user_n])
+				model.itemBias[r.item_n] += lr * (residual - reg*model.itemBias[r.item_n])
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown baseline method %d", config.Method)
+	}
+
+	return model, nil
+}
+
+// BaselineStrategy predicts b_ui = μ + b_u + b_i with regularized user and
+// item biases. It does not compare users or items, so T only selects the
+// engine it is used with.
+type BaselineStrategy[T Key] struct {
+	BaselineConfig
+
+	lazyModel[baselineModel]
+}
+
+func NewBaselineStrategy[T Key](options ...BaselineOption) *BaselineStrategy[T] {
+	return &BaselineStrategy[T]{BaselineConfig: newBaselineConfig(options)}
+}
+
+func (s *BaselineStrategy[T]) Fit(recEngine *RecEngine[T]) error {
+
+	model, err := fitBaseline(s.BaselineConfig, recEngine.PreferenceMatrix)
+
+	if err != nil {
+		return err
+	}
+
+	s.store(model)
+
+	return nil
+}
+
+// PredictRating returns NaN when the biases could not be fitted.
+func (s *BaselineStrategy[T]) PredictRating(recEngine *RecEngine[T], target_user User, target_item Item, explanation *Explanation) float64 {
+
+	model, err := s.loadOrFit(func() error { return s.Fit(recEngine) })
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("model could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	user_n := recEngine.PreferenceMatrix.ColKeyToIndex[target_user]
+	item_n := recEngine.PreferenceMatrix.RowKeyToIndex[target_item]
+
+	explanation.explainBiases(model, user_n, item_n)
+
+	return model.predict(user_n, item_n)
+}
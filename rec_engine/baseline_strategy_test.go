@@ -0,0 +1,259 @@
+package rec_engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+// additivePreferenceMatrix holds r_ui = 3 + b_u + b_i for every pair, the
+// user biases being 1, 0, -1 and the item biases 0.5, -0.5.
+func additivePreferenceMatrix(t *testing.T) *KeyedMatrix[float64, Item, User] {
+
+	t.Helper()
+
+	preferenceMatrix, err := NewKeyedMatrix[float64](NewMatrix([][]float64{
+		{4.5, 3.5, 2.5},
+		{3.5, 2.5, 1.5},
+	}), []Item{{Id: 1}, {Id: 2}}, []User{{Id: 1}, {Id: 2}, {Id: 3}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return preferenceMatrix
+}
+
+func assertBiases(t *testing.T, model *baselineModel, user_bias []float64, item_bias []float64, tolerance float64) {
+
+	t.Helper()
+
+	for user_n, want := range user_bias {
+		if math.Abs(model.userBias[user_n]-want) > tolerance {
+			t.Fatalf("user biases %v, want %v", model.userBias, user_bias)
+		}
+	}
+
+	for item_n, want := range item_bias {
+		if math.Abs(model.itemBias[item_n]-want) > tolerance {
+			t.Fatalf("item biases %v, want %v", model.itemBias, item_bias)
+		}
+	}
+}
+
+func TestBaselineALS(t *testing.T) {
+
+	preferenceMatrix := additivePreferenceMatrix(t)
+
+	// the user biases sum to 0, so undamped ALS is exact after one epoch
+	model, err := fitBaseline(newBaselineConfig([]BaselineOption{WithBaselineEpochs(1), WithDamping(0, 0)}), preferenceMatrix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if model.globalMean != 3 {
+		t.Fatalf("global mean %v, want 3", model.globalMean)
+	}
+	assertBiases(t, model, []float64{1, 0, -1}, []float64{0.5, -0.5}, 1e-12)
+
+	// an item residual sum of 1.5 over 3 users is shrunk to 1.5 / (3 + 3)
+	model, err = fitBaseline(newBaselineConfig([]BaselineOption{WithBaselineEpochs(1), WithDamping(0, 3)}), preferenceMatrix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBiases(t, model, []float64{1, 0, -1}, []float64{0.25, -0.25}, 1e-12)
+
+	if _, err := fitBaseline(newBaselineConfig([]BaselineOption{WithBaselineMethod(BaselineMethod(7))}), preferenceMatrix); err == nil {
+		t.Fatal("expected an error for an unknown method")
+	}
+}
+
+func TestBaselineSGD(t *testing.T) {
+
+	preferenceMatrix := additivePreferenceMatrix(t)
+
+	model, err := fitBaseline(newBaselineConfig([]BaselineOption{
+		WithBaselineMethod(BaselineSGD),
+		WithBaselineEpochs(2000),
+		WithBaselineLearningRate(0.05),
+		WithBaselineRegularization(0),
+	}), preferenceMatrix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without regularization the biases may trade a constant between users
+	// and items, only their sums are checked
+	for item_n := range preferenceMatrix.RowsN() {
+		for user_n := range preferenceMatrix.ColsN() {
+
+			if got, want := model.predict(user_n, item_n), preferenceMatrix.Get(item_n, user_n); math.Abs(got-want) > 1e-3 {
+				t.Fatalf("(%d, %d) = %v, want %v", user_n, item_n, got, want)
+			}
+		}
+	}
+
+	preferenceMatrix = randomPreferenceMatrix(t, rand.New(rand.NewSource(1)), 15, 10, 0.4)
+
+	fit := func(seed int64) *baselineModel {
+
+		model, err := fitBaseline(newBaselineConfig([]BaselineOption{WithBaselineMethod(BaselineSGD), WithBaselineSeed(seed)}), preferenceMatrix)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		return model
+	}
+
+	a, b, other := fit(3), fit(3), fit(4)
+
+	assertBiases(t, b, a.userBias, a.itemBias, 0)
+
+	if a.predict(0, 0) == other.predict(0, 0) {
+		t.Fatal("a different seed gave the same biases")
+	}
+}
+
+func TestBaselineStrategyUnknownMethod(t *testing.T) {
+
+	recEngine := NewRecEngine[User](additivePreferenceMatrix(t), NewBaselineStrategy[User](WithBaselineMethod(BaselineMethod(7))))
+
+	if rating, _ := recEngine.PredictRating(User{Id: 1}, Item{Id: 1}); !math.IsNaN(rating) {
+		t.Fatalf("rating %v, want NaN", rating)
+	}
+}
+
+// TestKNNBaselineResiduals checks that the neighbours predict their
+// deviations from the baseline, b_ui + Σ s_uv (r_vi - b_vi) / Σ |s_uv| for
+// users and the same over the items rated by the user.
+func TestKNNBaselineResiduals(t *testing.T) {
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(2)), 12, 10, 0.5)
+
+	baseline, err := fitBaseline(newBaselineConfig(nil), preferenceMatrix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	residual := func(item_n int, user_n int) float64 {
+		return preferenceMatrix.Get(item_n, user_n) - baseline.predict(user_n, item_n)
+	}
+
+	userBased := NewUserBasedStrategy(WithBaseline(), WithMinSimilarity(-1))
+	itemBased := NewItemBasedStrategy(WithBaseline(), WithMinSimilarity(-1))
+
+	userEngine := NewRecEngine[User](preferenceMatrix, userBased)
+	itemEngine := NewRecEngine[Item](preferenceMatrix, itemBased)
+
+	if err := userEngine.Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := itemEngine.Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// weighted mean of the residuals over the other objects of a line
+	weighted := func(n int, others int, similarity func(k int) float64, rated func(k int) bool, residual func(k int) float64) (float64, bool) {
+
+		sum, sum_of_dist := 0.0, 0.0
+
+		for k := range others {
+
+			if k == n || !rated(k) || math.IsNaN(similarity(k)) {
+				continue
+			}
+
+			sum += similarity(k) * residual(k)
+			sum_of_dist += math.Abs(similarity(k))
+		}
+
+		return sum / sum_of_dist, sum_of_dist != 0
+	}
+
+	checked := 0
+
+	for item_n, item := range preferenceMatrix.RowKeys {
+		for user_n, user := range preferenceMatrix.ColKeys {
+
+			if preferenceMatrix.Get(item_n, user_n) != 0 {
+				continue
+			}
+
+			users := userBased.SimilarityMatrix()
+			deviation, ok := weighted(user_n, preferenceMatrix.ColsN(),
+				func(k int) float64 { return users.Get(user_n, k) },
+				func(k int) bool { return preferenceMatrix.Get(item_n, k) != 0 },
+				func(k int) float64 { return residual(item_n, k) })
+
+			if ok {
+
+				want := baseline.predict(user_n, item_n) + deviation
+
+				if got, _ := userEngine.PredictRating(user, item); math.Abs(got-want) > 1e-9 {
+					t.Fatalf("user-based (%v, %v) = %v, want %v", user, item, got, want)
+				}
+				checked++
+			}
+
+			items := itemBased.SimilarityMatrix()
+			deviation, ok = weighted(item_n, preferenceMatrix.RowsN(),
+				func(k int) float64 { return items.Get(item_n, k) },
+				func(k int) bool { return preferenceMatrix.Get(k, user_n) != 0 },
+				func(k int) float64 { return residual(k, user_n) })
+
+			if ok {
+
+				want := baseline.predict(user_n, item_n) + deviation
+
+				if got, _ := itemEngine.PredictRating(user, item); math.Abs(got-want) > 1e-9 {
+					t.Fatalf("item-based (%v, %v) = %v, want %v", user, item, got, want)
+				}
+				checked++
+			}
+		}
+	}
+
+	if checked == 0 {
+		t.Fatal("no prediction was checked")
+	}
+}
+
+func TestKNNBaselineRefitsLazily(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(3))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 12, 10, 0.4)
+
+	strategy := NewUserBasedStrategy(WithBaseline(), WithMinSimilarity(-1))
+	recEngine := NewRecEngine[User](preferenceMatrix, strategy)
+
+	if err := recEngine.Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	changeRatings(t, rng, recEngine, 20)
+
+	if strategy.baseline.load() != nil || strategy.model.load() == nil {
+		t.Fatal("a changed rating has to drop only the baseline")
+	}
+
+	rebuilt := NewRecEngine[User](preferenceMatrix, NewUserBasedStrategy(WithBaseline(), WithMinSimilarity(-1)))
+
+	for _, item := range preferenceMatrix.RowKeys {
+		for _, user := range preferenceMatrix.ColKeys {
+
+			got, _ := recEngine.PredictRating(user, item)
+			want, _ := rebuilt.PredictRating(user, item)
+
+			if math.Abs(got-want) > 1e-9 {
+				t.Fatalf("(%v, %v) = %v after the changes, %v rebuilt", user, item, got, want)
+			}
+		}
+	}
+}
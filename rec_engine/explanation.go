@@ -0,0 +1,214 @@
+package rec_engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Contribution is a rating of a neighbour that took part in a prediction.
+// For user-based strategies User is the neighbour and Item the target item,
+// for item-based ones and Slope One Item is the neighbour item the target
+// user rated.
+type Contribution struct {
+	User User `json:"user"`
+	Item Item `json:"item"`
+	// similarity of the neighbour to the target, 0 for Slope One which
+	// weights by Support
+	Similarity float64 `json:"similarity"`
+	Rating     float64 `json:"rating"`
+	// deviation of Rating from the neighbour's expected rating, for Slope One
+	// the mean difference between the target item and the neighbour item
+	Deviation float64 `json:"deviation"`
+	// share of the neighbour in the total weight of the prediction
+	Weight float64 `json:"weight"`
+	// amount of users who rated both items, Slope One only
+	Support int `json:"support,omitempty"`
+}
+
+// Explanation tells how a strategy arrived at a predicted rating.
+type Explanation struct {
+	User     User    `json:"user"`
+	Item     Item    `json:"item"`
+	Strategy string  `json:"strategy"`
+	Rating   float64 `json:"rating"`
+	// rating the neighbours' deviations are added to, e.g. the user's mean
+	// rating or the baseline, only set by the strategies which have one
+	Base       float64        `json:"base"`
+	Neighbours []Contribution `json:"neighbours,omitempty"`
+	// why the strategy didn't use the neighbours or the model, empty when it
+	// did
+	Fallback string `json:"fallback,omitempty"`
+	// terms of model-based predictions by name, e.g. "user bias"
+	Components map[string]float64 `json:"components,omitempty"`
+
+	// whether Base was set
+	hasBase bool
+}
+
+// TopNeighbours returns the n neighbours with the largest weight, e.g. to
+// show "because you liked X".
+func (e *Explanation) TopNeighbours(n int) []Contribution {
+
+	neighbours := append([]Contribution(nil), e.Neighbours...)
+
+	sort.SliceStable(neighbours, func(i, j int) bool {
+		return neighbours[i].Weight > neighbours[j].Weight
+	})
+
+	return neighbours[:min(n, len(neighbours))]
+}
+
+// JSON returns the explanation as indented JSON, NaN values becoming null.
+// base is left out for the strategies without one, e.g. BaselineStrategy,
+// whose components sum to the rating instead.
+func (e *Explanation) JSON() ([]byte, error) {
+
+	type explanation struct {
+		*Explanation
+		Rating *float64 `json:"rating"`
+		// a nil interface is omitted, a nil *float64 in it encoded as null
+		Base any `json:"base,omitempty"`
+	}
+
+	encoded := explanation{Explanation: e, Rating: finite(e.Rating)}
+
+	if e.hasBase {
+		encoded.Base = finite(e.Base)
+	}
+
+	return json.MarshalIndent(encoded, "", "  ")
+}
+
+func finite(value float64) *float64 {
+
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+
+	return &value
+}
+
+func (e *Explanation) String() string {
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s → %s: %.4f (%s)\n", e.User, e.Item, e.Rating, e.Strategy)
+
+	if e.Fallback != "" {
+		fmt.Fprintf(&sb, "fallback: %s\n", e.Fallback)
+	}
+
+	if len(e.Neighbours) > 0 {
+
+		if e.hasBase {
+			fmt.Fprintf(&sb, "base: %.4f\n", e.Base)
+		}
+
+		for _, c := range e.Neighbours {
+
+			fmt.Fprintf(&sb, "  %s rated %s %.2f, similarity %.4f, deviation %+.4f, weight %.1f%%",
+				c.User, c.Item, c.Rating, c.Similarity, c.Deviation, 100*c.Weight)
+
+			if c.Support > 0 {
+				fmt.Fprintf(&sb, ", support %d", c.Support)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	for _, name := range componentNames {
+
+		if value, ok := e.Components[name]; ok {
+			fmt.Fprintf(&sb, "%s: %.4f\n", name, value)
+		}
+	}
+
+	return sb.String()
+}
+
+// component names in the order they are printed
+const (
+	componentGlobalMean = "global mean"
+	componentUserBias   = "user bias"
+	componentItemBias   = "item bias"
+	componentFactors    = "factors"
+)
+
+var componentNames = []string{componentGlobalMean, componentUserBias, componentItemBias, componentFactors}
+
+// explainBiases fills the components of a baseline prediction.
+func (e *Explanation) explainBiases(model *baselineModel, user_n int, item_n int) {
+
+	if e == nil {
+		return
+	}
+
+	e.Components = map[string]float64{
+		componentGlobalMean: model.globalMean,
+		componentUserBias:   model.userBias[user_n],
+		componentItemBias:   model.itemBias[item_n],
+	}
+}
+
+// explainFactors fills the components of a prediction of model, factors
+// being the dot product of the user and the item factors.
+func (e *Explanation) explainFactors(model *factorModel, user_n int, item_n int) {
+
+	if e == nil {
+		return
+	}
+
+	factors := 0.0
+
+	for f, value := range model.userFactors[user_n] {
+		factors += value * model.itemFactors[item_n][f]
+	}
+
+	e.Components = map[string]float64{
+		componentGlobalMean: model.globalMean,
+		componentUserBias:   model.userBias[user_n],
+		componentItemBias:   model.itemBias[item_n],
+		componentFactors:    factors,
+	}
+}
+
+// fallback records the reason a prediction fell back, when it is explained.
+func (e *Explanation) fallback(reason string) {
+
+	if e != nil {
+		e.Fallback = reason
+	}
+}
+
+// fallbackTo records that the prediction fell back to the rating named by
+// base, the neighbours found not being used.
+func (e *Explanation) fallbackTo(reason string, base string, rating float64) {
+
+	if e == nil {
+		return
+	}
+
+	e.Fallback = fmt.Sprintf("%s, falling back to the %s", reason, base)
+	e.Base = rating
+	e.hasBase = true
+	e.Neighbours = nil
+}
+
+// weigh sets the base rating and the weight shares of the neighbours, whose
+// absolute similarities sum to sum_of_dist.
+func (e *Explanation) weigh(base float64, sum_of_dist float64) {
+
+	if e == nil {
+		return
+	}
+
+	e.Base = base
+	e.hasBase = true
+
+	for k := range e.Neighbours {
+		e.Neighbours[k].Weight = math.Abs(e.Neighbours[k].Similarity) / sum_of_dist
+	}
+}
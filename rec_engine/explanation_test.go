@@ -0,0 +1,156 @@
+package rec_engine
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestTopNeighbours(t *testing.T) {
+
+	explanation := &Explanation{Neighbours: []Contribution{
+		{User: User{Id: 1}, Weight: 0.2},
+		{User: User{Id: 2}, Weight: 0.5},
+		{User: User{Id: 3}, Weight: 0.15},
+		{User: User{Id: 4}, Weight: 0.15},
+	}}
+
+	// ties keep their order
+	top := explanation.TopNeighbours(3)
+
+	if len(top) != 3 || top[0].User.Id != 2 || top[1].User.Id != 1 || top[2].User.Id != 3 {
+		t.Fatalf("top 3 %v, want U2, U1, U3", top)
+	}
+
+	if len(explanation.TopNeighbours(10)) != 4 {
+		t.Fatalf("top 10 of 4 neighbours %v", explanation.TopNeighbours(10))
+	}
+
+	if explanation.Neighbours[0].User.Id != 1 {
+		t.Fatal("TopNeighbours reordered the neighbours of the explanation")
+	}
+}
+
+func decodeExplanation(t *testing.T, explanation *Explanation) map[string]any {
+
+	t.Helper()
+
+	encoded, err := explanation.JSON()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]any
+
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("%v in\n%s", err, encoded)
+	}
+
+	return decoded
+}
+
+func TestExplanationJSON(t *testing.T) {
+
+	// NaN isn't valid JSON, it becomes null
+	explanation := &Explanation{User: User{Id: 1}, Item: Item{Id: 2}, Rating: math.NaN()}
+	explanation.fallbackTo("no neighbours", "user's mean rating", math.NaN())
+
+	decoded := decodeExplanation(t, explanation)
+
+	for _, key := range []string{"rating", "base"} {
+		if value, ok := decoded[key]; !ok || value != nil {
+			t.Fatalf("%s: %v, want null", key, decoded)
+		}
+	}
+
+	if decoded["fallback"] != "no neighbours, falling back to the user's mean rating" {
+		t.Fatalf("fallback %v", decoded["fallback"])
+	}
+
+	// the components of BaselineStrategy sum to the rating, there is no base
+	recEngine := NewRecEngine[User](additivePreferenceMatrix(t), NewBaselineStrategy[User]())
+	explanation, err := recEngine.ExplainRating(User{Id: 1}, Item{Id: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded = decodeExplanation(t, explanation)
+
+	if _, ok := decoded["base"]; ok {
+		t.Fatalf("base %v for BaselineStrategy", decoded["base"])
+	}
+
+	sum := 0.0
+
+	for _, value := range decoded["components"].(map[string]any) {
+		sum += value.(float64)
+	}
+
+	if math.Abs(sum-decoded["rating"].(float64)) > 1e-12 {
+		t.Fatalf("components %v sum to %v, not the rating %v", decoded["components"], sum, decoded["rating"])
+	}
+
+	// a weighed prediction has a base
+	explanation = &Explanation{Rating: 4, Neighbours: []Contribution{{Similarity: 0.5, Rating: 5, Deviation: 1}}}
+	explanation.weigh(3, 0.5)
+
+	decoded = decodeExplanation(t, explanation)
+
+	if decoded["base"] != 3.0 || decoded["neighbours"].([]any)[0].(map[string]any)["weight"] != 1.0 {
+		t.Fatalf("base %v and neighbours %v, want 3 and weight 1", decoded["base"], decoded["neighbours"])
+	}
+}
+
+func TestExplanationString(t *testing.T) {
+
+	explanation := &Explanation{
+		User:     User{Id: 1},
+		Item:     Item{Id: 2},
+		Strategy: "UserBasedStrategy",
+		Rating:   4.1,
+		Neighbours: []Contribution{
+			{User: User{Id: 2}, Item: Item{Id: 2}, Similarity: 0.8, Rating: 5, Deviation: 1},
+			{User: User{Id: 3}, Item: Item{Id: 2}, Similarity: -0.2, Rating: 2, Deviation: -1},
+		},
+	}
+	explanation.weigh(3.5, 1)
+
+	want := "U1 → P2: 4.1000 (UserBasedStrategy)\n" +
+		"base: 3.5000\n" +
+		"  U2 rated P2 5.00, similarity 0.8000, deviation +1.0000, weight 80.0%\n" +
+		"  U3 rated P2 2.00, similarity -0.2000, deviation -1.0000, weight 20.0%\n"
+
+	if got := explanation.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Slope One has no base but the support of every neighbour
+	explanation = &Explanation{
+		User:       User{Id: 1},
+		Item:       Item{Id: 2},
+		Strategy:   "SlopeOneStrategy",
+		Rating:     4.5,
+		Neighbours: []Contribution{{User: User{Id: 1}, Item: Item{Id: 3}, Rating: 4, Deviation: 0.5, Weight: 1, Support: 3}},
+	}
+
+	want = "U1 → P2: 4.5000 (SlopeOneStrategy)\n" +
+		"  U1 rated P3 4.00, similarity 0.0000, deviation +0.5000, weight 100.0%, support 3\n"
+
+	if got := explanation.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	explanation = &Explanation{User: User{Id: 1}, Item: Item{Id: 1}, Strategy: "BaselineStrategy", Rating: 4.5}
+	explanation.explainBiases(&baselineModel{globalMean: 3, userBias: []float64{1}, itemBias: []float64{0.5}}, 0, 0)
+
+	want = "U1 → P1: 4.5000 (BaselineStrategy)\n" +
+		"global mean: 3.0000\n" +
+		"user bias: 1.0000\n" +
+		"item bias: 0.5000\n"
+
+	if got := explanation.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
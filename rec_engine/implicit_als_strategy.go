@@ -0,0 +1,323 @@
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+// IterationReport describes one iteration of an iterative fit.
+type IterationReport struct {
+	Iteration int
+	// value of the objective after the iteration
+	Loss    float64
+	Elapsed time.Duration
+}
+
+func (r IterationReport) String() string {
+	return fmt.Sprintf("iteration %d: loss %f (%v)", r.Iteration, r.Loss, r.Elapsed)
+}
+
+type ImplicitALSConfig struct {
+	FactorConfig
+
+	// confidence of a rating r is 1 + Alpha·r
+	Alpha float64
+	// amount of goroutines solving the least squares
+	Workers int
+}
+
+type ImplicitALSOption func(*ImplicitALSConfig)
+
+func WithALSFactors(factors int) ImplicitALSOption {
+	return func(c *ImplicitALSConfig) { c.Factors = factors }
+}
+
+func WithALSRegularization(lambda float64) ImplicitALSOption {
+	return func(c *ImplicitALSConfig) { c.Regularization = lambda }
+}
+
+func WithALSIterations(iterations int) ImplicitALSOption {
+	return func(c *ImplicitALSConfig) { c.Epochs = iterations }
+}
+
+func WithALSInitStdDev(std_dev float64) ImplicitALSOption {
+	return func(c *ImplicitALSConfig) { c.InitStdDev = std_dev }
+}
+
+func WithALSSeed(seed int64) ImplicitALSOption {
+	return func(c *ImplicitALSConfig) { c.Seed = seed }
+}
+
+func WithAlpha(alpha float64) ImplicitALSOption {
+	return func(c *ImplicitALSConfig) { c.Alpha = alpha }
+}
+
+func WithWorkers(workers int) ImplicitALSOption {
+	return func(c *ImplicitALSConfig) { c.Workers = workers }
+}
+
+// ImplicitALSStrategy is the implicit feedback factorization of Hu, Koren and
+// Volinsky. Every positive entry r_ui of the preference matrix, e.g. a click
+// count, is a preference p_ui = 1 with confidence c_ui = 1 + Alpha·r_ui, all
+// other entries are p_ui = 0 with confidence 1, and
+//
+//	Σ c_ui (p_ui - x_u·y_i)² + Regularization·(Σ ||x_u||² + Σ ||y_i||²)
+//
+// is minimized by alternately solving for the user and the item factors.
+// PredictRating returns the preference score x_u·y_i, not a rating, so it is
+// meant for ranking with MakeRecommendationTopN. Epochs is the amount of
+// iterations.
+type ImplicitALSStrategy[T Key] struct {
+	ImplicitALSConfig
+
+	lazyModel[factorModel]
+
+	reportMu sync.Mutex
+	report   []IterationReport
+}
+
+// NewImplicitALSStrategy defaults to 20 factors, regularization 0.1, alpha 40,
+// 15 iterations and GOMAXPROCS workers.
+func NewImplicitALSStrategy[T Key](options ...ImplicitALSOption) *ImplicitALSStrategy[T] {
+
+	config := ImplicitALSConfig{
+		FactorConfig: FactorConfig{
+			Factors:        20,
+			Regularization: 0.1,
+			Epochs:         15,
+			InitStdDev:     0.01,
+			Seed:           1,
+		},
+		Alpha:   40,
+		Workers: runtime.GOMAXPROCS(0),
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return &ImplicitALSStrategy[T]{ImplicitALSConfig: config}
+}
+
+// Fit learns the model from the positive entries of the preference matrix.
+func (s *ImplicitALSStrategy[T]) Fit(recEngine *RecEngine[T]) error {
+
+	preferenceMatrix := recEngine.PreferenceMatrix
+	items_n, users_n := preferenceMatrix.RowsN(), preferenceMatrix.ColsN()
+
+	// the positive entries of every user and every item
+	userIndices, userValues := make([][]int, users_n), make([][]float64, users_n)
+	itemIndices, itemValues := make([][]int, items_n), make([][]float64, items_n)
+
+	for item_n := range items_n {
+
+		indices, values := preferenceMatrix.GetSparseRow(item_n)
+
+		for k, user_n := range indices {
+
+			if values[k] > 0 {
+
+				userIndices[user_n] = append(userIndices[user_n], item_n)
+				userValues[user_n] = append(userValues[user_n], values[k])
+				itemIndices[item_n] = append(itemIndices[item_n], user_n)
+				itemValues[item_n] = append(itemValues[item_n], values[k])
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(s.Seed))
+
+	model := &factorModel{
+		userBias:    make([]float64, users_n),
+		itemBias:    make([]float64, items_n),
+		userFactors: randomFactors(rng, users_n, s.Factors, s.InitStdDev),
+		itemFactors: randomFactors(rng, items_n, s.Factors, s.InitStdDev),
+	}
+
+	report := make([]IterationReport, 0, s.Epochs)
+
+	for iteration := range s.Epochs {
+
+		start := time.Now()
+
+		if err := s.solve(model.userFactors, model.itemFactors, userIndices, userValues); err != nil {
+			return err
+		}
+
+		if err := s.solve(model.itemFactors, model.userFactors, itemIndices, itemValues); err != nil {
+			return err
+		}
+
+		report = append(report, IterationReport{
+			Iteration: iteration + 1,
+			Loss:      s.loss(model, userIndices, userValues),
+			Elapsed:   time.Since(start),
+		})
+	}
+
+	s.store(model)
+
+	s.reportMu.Lock()
+	s.report = report
+	s.reportMu.Unlock()
+
+	return nil
+}
+
+// solve replaces every row x of factors, fixed holding the factors on the
+// other side, with the least squares solution
+//
+//	x = (YᵀY + Yᵀ(C - I)Y + λI)⁻¹ YᵀCp
+//
+// the rows being split between Workers goroutines.
+func (s *ImplicitALSStrategy[T]) solve(factors [][]float64, fixed [][]float64, indices [][]int, values [][]float64) error {
+
+	gramian := s.gramian(fixed)
+
+	rows := make(chan int)
+	errs := make(chan error, max(s.Workers, 1))
+
+	var wg sync.WaitGroup
+
+	for range max(s.Workers, 1) {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			a := NewZeroMatrix[float64](s.Factors, s.Factors)
+			b := make([]float64, s.Factors)
+
+			for n := range rows {
+
+				for i := range s.Factors {
+					for k := range s.Factors {
+						a.Set(i, k, gramian.Get(i, k))
+					}
+					a.Set(i, i, a.Get(i, i)+s.Regularization)
+					b[i] = 0
+				}
+
+				for k, other := range indices[n] {
+
+					y := fixed[other]
+					confidence := 1 + s.Alpha*values[n][k]
+
+					for i := range s.Factors {
+
+						for j := range i + 1 {
+							a.Set(i, j, a.Get(i, j)+(confidence-1)*y[i]*y[j])
+						}
+						b[i] += confidence * y[i]
+					}
+				}
+
+				l, err := Cholesky(a)
+
+				if err == nil {
+					factors[n], err = CholeskySolve(l, b)
+				}
+
+				if err != nil {
+					errs <- err
+					for range rows {
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	for n := range factors {
+		rows <- n
+	}
+	close(rows)
+
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// gramian returns YᵀY of the rows of factors.
+func (s *ImplicitALSStrategy[T]) gramian(factors [][]float64) *Matrix[float64] {
+
+	if len(factors) == 0 || s.Factors == 0 {
+		return NewZeroMatrix[float64](s.Factors, s.Factors)
+	}
+
+	y := NewMatrix(factors)
+	gramian, _ := y.Transpose().Mul(y)
+
+	return gramian
+}
+
+// loss evaluates the objective without visiting every user-item pair: the
+// squared scores of all pairs are trace(XᵀX·YᵀY), which is then corrected on
+// the positive entries.
+func (s *ImplicitALSStrategy[T]) loss(model *factorModel, indices [][]int, values [][]float64) float64 {
+
+	userGramian := s.gramian(model.userFactors)
+	itemGramian := s.gramian(model.itemFactors)
+
+	loss := 0.0
+
+	for i := range s.Factors {
+		for j := range s.Factors {
+			loss += userGramian.Get(i, j) * itemGramian.Get(j, i)
+		}
+	}
+
+	for user_n := range indices {
+		for k, item_n := range indices[user_n] {
+
+			score := model.predict(user_n, item_n)
+			confidence := 1 + s.Alpha*values[user_n][k]
+
+			loss += confidence*(1-score)*(1-score) - score*score
+		}
+	}
+
+	penalty := 0.0
+
+	for i := range s.Factors {
+		penalty += userGramian.Get(i, i) + itemGramian.Get(i, i)
+	}
+
+	return loss + s.Regularization*penalty
+}
+
+// Report returns the iterations of the last Fit.
+func (s *ImplicitALSStrategy[T]) Report() []IterationReport {
+
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+
+	return append([]IterationReport(nil), s.report...)
+}
+
+// PredictRating returns NaN when the factors could not be solved for.
+func (s *ImplicitALSStrategy[T]) PredictRating(recEngine *RecEngine[T], target_user User, target_item Item, explanation *Explanation) float64 {
+
+	model, err := s.loadOrFit(func() error { return s.Fit(recEngine) })
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("model could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	user_n := recEngine.PreferenceMatrix.ColKeyToIndex[target_user]
+	item_n := recEngine.PreferenceMatrix.RowKeyToIndex[target_item]
+
+	explanation.explainFactors(model, user_n, item_n)
+
+	return model.predict(user_n, item_n)
+}
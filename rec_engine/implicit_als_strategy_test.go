@@ -0,0 +1,119 @@
+package rec_engine
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+func TestImplicitALSLossDoesNotIncrease(t *testing.T) {
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(1)), 20, 15, 0.3)
+
+	strategy := NewImplicitALSStrategy[User](WithALSFactors(5), WithALSIterations(10))
+
+	if err := NewRecEngine[User](preferenceMatrix, strategy).Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := strategy.Report()
+
+	if len(report) != 10 {
+		t.Fatalf("%d iterations reported, want 10", len(report))
+	}
+
+	// every half step minimizes the loss over one side exactly
+	for i := 1; i < len(report); i++ {
+		if report[i].Loss > report[i-1].Loss*(1+1e-9) {
+			t.Fatalf("loss rose from %v to %v at iteration %d", report[i-1].Loss, report[i].Loss, report[i].Iteration)
+		}
+	}
+}
+
+func TestImplicitALSWorkersAgree(t *testing.T) {
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(2)), 20, 15, 0.3)
+
+	fit := func(workers int) *RecEngine[User] {
+
+		recEngine := NewRecEngine[User](preferenceMatrix, NewImplicitALSStrategy[User](WithALSFactors(5), WithALSIterations(5), WithWorkers(workers)))
+
+		if err := recEngine.Fit(); err != nil {
+			t.Fatal(err)
+		}
+		return recEngine
+	}
+
+	single, parallel := fit(1), fit(4)
+
+	for _, item := range preferenceMatrix.RowKeys {
+		for _, user := range preferenceMatrix.ColKeys {
+
+			want, _ := single.PredictRating(user, item)
+			got, _ := parallel.PredictRating(user, item)
+
+			if got != want {
+				t.Fatalf("(%v, %v) = %v with 4 workers, %v with 1", user, item, got, want)
+			}
+		}
+	}
+}
+
+func TestImplicitALSRanksWithinGroup(t *testing.T) {
+
+	items := []Item{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}, {Id: 5}, {Id: 6}}
+	users := []User{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}, {Id: 5}, {Id: 6}}
+
+	// U1-U3 use P1-P3 and U4-U6 use P4-P6, U2 hasn't used P3 and U5 P6 yet
+	preferenceMatrix, err := NewKeyedMatrix[float64](NewMatrix([][]float64{
+		{3, 2, 4, 0, 0, 0},
+		{1, 5, 2, 0, 0, 0},
+		{2, 0, 3, 0, 0, 0},
+		{0, 0, 0, 2, 3, 1},
+		{0, 0, 0, 4, 1, 2},
+		{0, 0, 0, 1, 0, 3},
+	}), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recEngine := NewRecEngine[User](preferenceMatrix, NewImplicitALSStrategy[User](WithALSFactors(2), WithALSIterations(20)))
+
+	for _, test := range []struct {
+		user  User
+		want  Item
+		other Item
+	}{
+		{users[1], items[2], items[5]},
+		{users[4], items[5], items[2]},
+	} {
+
+		top, err := recEngine.MakeRecommendationTopN(test.user, 1)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(top) != 1 || top[0].Item != test.want {
+			t.Fatalf("top item of %v: %v, want %v", test.user, top, test.want)
+		}
+
+		in_group, err := recEngine.PredictRating(test.user, test.want)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out_of_group, err := recEngine.PredictRating(test.user, test.other)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if in_group <= out_of_group {
+			t.Fatalf("%v: score %v for %v, not above %v for %v", test.user, in_group, test.want, out_of_group, test.other)
+		}
+	}
+}
@@ -1,107 +1,133 @@
-package rec_engine
-
-import (
-	"fmt"
-	"math"
-
-	. "github.com/PetrDoroshev/RS/matrix"
-	"github.com/PetrDoroshev/RS/utils"
-)
-
-type ItemBasedStrategy struct{}
-
-func (s ItemBasedStrategy) BuildSimilarityMatrix(objects_to_comp []Item, preferenceMatrix *KeyedMatrix[float64, Item, User]) *KeyedMatrix[float64, Item, Item] {
-
-	similarityMatrix, _ := NewKeyedMatrix(*NewZeroMatrix[float64](len(objects_to_comp), len(objects_to_comp)),
-		objects_to_comp,
-		objects_to_comp,
-	)
-
-	for i, item_1 := range objects_to_comp {
-
-		for k := i + 1; k < len(objects_to_comp); k++ {
-
-			item_2 := objects_to_comp[k]
-
-			similarity, _ := utils.CosSimilarity(preferenceMatrix.GetRowByKey(item_1),
-				preferenceMatrix.GetRowByKey(item_2))
-
-			similarityMatrix.Set(i, k, similarity)
-			similarityMatrix.Set(k, i, similarity)
-		}
-	}
-
-	return similarityMatrix
-}
-
-func (s ItemBasedStrategy) PredictRating(recEngine *RecEngine[Item], target_user User, target_item Item, output bool) float64 {
-
-	similarityMatrix := s.BuildSimilarityMatrix(recEngine.PreferenceMatrix.RowKeys, &recEngine.PreferenceMatrix)
-
-	if output {
-		fmt.Println("\nМатрица подобия:")
-		PrintSimilarityMatrix(similarityMatrix)
-	}
-
-	nearest_neighbours := []Item{}
-	similarity_threshold := 0.85
-
-	for i, dist := range similarityMatrix.GetRowByKey(target_item) {
-
-		i := similarityMatrix.RowKeys[i]
-
-		if dist >= similarity_threshold && i != target_item {
-
-			nearest_neighbours = append(nearest_neighbours, i)
-		}
-
-	}
-
-	if output {
-		fmt.Printf("\nБлижайшие соседи %s (< %.2f):\n", target_item, similarity_threshold)
-		fmt.Println(nearest_neighbours)
-	}
-
-	n := 0
-	for _, i := range nearest_neighbours {
-		if recEngine.PreferenceMatrix.GetByKey(i, target_user) != 0 {
-			n++
-		}
-	}
-
-	sum_of_dist := 0.0
-	sum_of_rating := 0.0
-
-	if n > 0 {
-
-		for _, i := range nearest_neighbours {
-
-			if recEngine.PreferenceMatrix.GetByKey(i, target_user) != 0 {
-				sum_of_rating += recEngine.PreferenceMatrix.GetByKey(i, target_user) * similarityMatrix.GetByKey(target_item, i)
-				sum_of_dist += math.Abs(similarityMatrix.GetByKey(target_item, i))
-			}
-
-		}
-	} else {
-		//fmt.Println(2)
-		for _, i := range nearest_neighbours {
-
-			users_count := 0
-
-			for _, u := range recEngine.PreferenceMatrix.ColKeys {
-
-				if recEngine.PreferenceMatrix.GetByKey(i, u) != 0 {
-					sum_of_rating += recEngine.PreferenceMatrix.GetByKey(i, u) * similarityMatrix.GetByKey(target_item, i)
-					users_count++
-				}
-
-			}
-			sum_of_rating /= float64(users_count)
-			sum_of_dist += math.Abs(similarityMatrix.GetByKey(target_item, i))
-		}
-		//fmt.Println(sum_of_rating)
-		//fmt.Println(sum_of_dist)
-	}
-
-	return sum_of_rating / sum_of_dist
-}
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+type ItemBasedStrategy struct {
+	NeighbourhoodConfig
+	neighbourhoodModel[Item]
+}
+
+// NewItemBasedStrategy defaults to neighbours with similarity >= 0.85 among
+// the items rated by the target user.
+func NewItemBasedStrategy(options ...NeighbourhoodOption) *ItemBasedStrategy {
+	return &ItemBasedStrategy{NeighbourhoodConfig: newNeighbourhoodConfig(0.85, options)}
+}
+
+func (s *ItemBasedStrategy) BuildSimilarityMatrix(objects_to_comp []Item, preferenceMatrix *KeyedMatrix[float64, Item, User]) (*KeyedMatrix[float64, Item, Item], error) {
+
+	similarityMatrix, _, err := s.BuildSimilarityMatrixWithCounts(objects_to_comp, preferenceMatrix)
+	return similarityMatrix, err
+}
+
+// BuildSimilarityMatrixWithCounts also returns the amount of entries rated by
+// both objects of every pair, the diagonal holding the amount rated by each.
+func (s *ItemBasedStrategy) BuildSimilarityMatrixWithCounts(objects_to_comp []Item, preferenceMatrix *KeyedMatrix[float64, Item, User]) (*KeyedMatrix[float64, Item, Item], *KeyedMatrix[int, Item, Item], error) {
+
+	return buildSimilarityMatrix(objects_to_comp,
+		preferenceMatrix.GetSparseRowByKey,
+		func() []float64 { return meanRatings(preferenceMatrix.ColsN(), preferenceMatrix.GetSparseCol) },
+		s.SimilarityConfig,
+	)
+}
+
+// Fit computes the item-item similarity model used by PredictRating,
+// truncated to ModelTopK neighbours per item when it is set.
+func (s *ItemBasedStrategy) Fit(recEngine *RecEngine[Item]) error {
+
+	preferenceMatrix := recEngine.PreferenceMatrix
+	return s.fit(s.NeighbourhoodConfig, preferenceMatrix, preferenceMatrix.RowKeys, s.BuildSimilarityMatrixWithCounts, preferenceMatrix.GetSparseRow)
+}
+
+// UpdateRating applies a rating changed by the user to the similarities of
+// the item with every other item.
+func (s *ItemBasedStrategy) UpdateRating(recEngine *RecEngine[Item], user User, item Item, old_rating float64, new_rating float64) {
+
+	indices, values := recEngine.PreferenceMatrix.GetSparseColByKey(user)
+	s.update(recEngine.PreferenceMatrix.RowKeyToIndex[item], indices, values, old_rating, new_rating)
+}
+
+// PredictRating weighs the ratings the user gave to the most similar items,
+// or their deviations from the baseline. It returns NaN when the similarities
+// could not be computed.
+func (s *ItemBasedStrategy) PredictRating(recEngine *RecEngine[Item], target_user User, target_item Item, explanation *Explanation) float64 {
+
+	similarityMatrix, err := s.fittedMatrix(func() error { return s.Fit(recEngine) })
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("similarity model could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	indices, similarities := similarityMatrix.GetSparseRowByKey(target_item)
+	items := make([]Item, len(indices))
+
+	for k, col_n := range indices {
+		items[k] = similarityMatrix.ColKeys[col_n]
+	}
+
+	nearest_neighbours := selectNeighbours(s.NeighbourhoodConfig,
+		items,
+		similarities,
+		target_item,
+		func(i Item) bool {
+			rating, ok := recEngine.PreferenceMatrix.LookupByKey(i, target_user)
+			return ok && rating != 0
+		},
+	)
+
+	// without the baseline the neighbours predict the rating itself
+	expected_rating := func(i Item) float64 { return 0 }
+	baseline, err := s.fittedBaseline(s.NeighbourhoodConfig, recEngine.PreferenceMatrix)
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("baseline could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	if baseline != nil {
+		expected_rating = func(i Item) float64 {
+			return baseline.predictByKey(recEngine.PreferenceMatrix, target_user, i)
+		}
+	}
+
+	sum_of_dist := 0.0
+	sum_of_rating := 0.0
+
+	for _, i := range nearest_neighbours {
+
+		item_rating, _ := recEngine.PreferenceMatrix.LookupByKey(i.Key, target_user)
+
+		sum_of_rating += (item_rating - expected_rating(i.Key)) * i.Similarity
+		sum_of_dist += math.Abs(i.Similarity)
+
+		if explanation != nil {
+			explanation.Neighbours = append(explanation.Neighbours, Contribution{
+				User:       target_user,
+				Item:       i.Key,
+				Similarity: i.Similarity,
+				Rating:     item_rating,
+				Deviation:  item_rating - expected_rating(i.Key),
+			})
+		}
+	}
+
+	if !s.enoughNeighbours(len(nearest_neighbours)) || sum_of_dist == 0 {
+
+		reason := s.fallbackReason(len(nearest_neighbours), "rated by the user")
+
+		if baseline != nil {
+			explanation.fallbackTo(reason, "baseline", expected_rating(target_item))
+			return expected_rating(target_item)
+		}
+		return recEngine.explainedBaselineRating(target_user, target_item, explanation, reason)
+	}
+
+	explanation.weigh(expected_rating(target_item), sum_of_dist)
+
+	return expected_rating(target_item) + sum_of_rating/sum_of_dist
+}
@@ -0,0 +1,70 @@
+package rec_engine
+
+import (
+	"errors"
+	"sync"
+)
+
+var errInvalidatedWhileFitting = errors.New("model was invalidated while it was being fitted")
+
+// lazyModel holds the fitted model of a strategy. Strategies embed it to be
+// fitted on the first prediction and again after Invalidate, so RecEngine.Fit
+// is never required.
+type lazyModel[M any] struct {
+	mu    sync.Mutex
+	model *M
+}
+
+// load returns the model, nil when it isn't fitted.
+func (l *lazyModel[M]) load() *M {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.model
+}
+
+func (l *lazyModel[M]) store(model *M) {
+
+	l.mu.Lock()
+	l.model = model
+	l.mu.Unlock()
+}
+
+// Invalidate drops the fitted model, it is fitted again on the next
+// prediction.
+func (l *lazyModel[M]) Invalidate() {
+	l.store(nil)
+}
+
+// loadOrFit returns the model, first calling fit, which has to store it, when
+// there is none.
+func (l *lazyModel[M]) loadOrFit(fit func() error) (*M, error) {
+
+	if model := l.load(); model != nil {
+		return model, nil
+	}
+
+	if err := fit(); err != nil {
+		return nil, err
+	}
+
+	if model := l.load(); model != nil {
+		return model, nil
+	}
+
+	return nil, errInvalidatedWhileFitting
+}
+
+// update replaces the model with the one change returns, nil invalidating it.
+// change is called with the lock held and only when there is a model, so it
+// may modify the model in place.
+func (l *lazyModel[M]) update(change func(model *M) *M) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.model != nil {
+		l.model = change(l.model)
+	}
+}
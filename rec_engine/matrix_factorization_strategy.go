@@ -0,0 +1,220 @@
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// FactorConfig holds the settings shared by MatrixFactorizationStrategy and
+// ImplicitALSStrategy.
+type FactorConfig struct {
+	// amount of latent factors of every user and item
+	Factors int
+	// L2 penalty of the factors, and of the biases for SGD
+	Regularization float64
+	// amount of passes over the observed ratings, or iterations of ALS
+	Epochs int
+	// standard deviation of the normally distributed initial factors
+	InitStdDev float64
+	// seed of the RNG initializing the factors, and shuffling the ratings for
+	// SGD
+	Seed int64
+}
+
+type MatrixFactorizationConfig struct {
+	FactorConfig
+
+	// step of the stochastic gradient descent
+	LearningRate float64
+}
+
+type MatrixFactorizationOption func(*MatrixFactorizationConfig)
+
+func WithFactors(factors int) MatrixFactorizationOption {
+	return func(c *MatrixFactorizationConfig) { c.Factors = factors }
+}
+
+func WithLearningRate(rate float64) MatrixFactorizationOption {
+	return func(c *MatrixFactorizationConfig) { c.LearningRate = rate }
+}
+
+func WithRegularization(lambda float64) MatrixFactorizationOption {
+	return func(c *MatrixFactorizationConfig) { c.Regularization = lambda }
+}
+
+func WithEpochs(epochs int) MatrixFactorizationOption {
+	return func(c *MatrixFactorizationConfig) { c.Epochs = epochs }
+}
+
+func WithInitStdDev(std_dev float64) MatrixFactorizationOption {
+	return func(c *MatrixFactorizationConfig) { c.InitStdDev = std_dev }
+}
+
+func WithSeed(seed int64) MatrixFactorizationOption {
+	return func(c *MatrixFactorizationConfig) { c.Seed = seed }
+}
+
+// factorModel holds the biases and latent factors learned for the users and
+// items of a preference matrix, indexed by their column and row index there.
+type factorModel struct {
+	globalMean float64
+
+	userBias []float64
+	itemBias []float64
+
+	userFactors [][]float64
+	itemFactors [][]float64
+}
+
+func (m *factorModel) predict(user_n int, item_n int) float64 {
+
+	prediction := m.globalMean + m.userBias[user_n] + m.itemBias[item_n]
+
+	for f, value := range m.userFactors[user_n] {
+		prediction += value * m.itemFactors[item_n][f]
+	}
+
+	return prediction
+}
+
+// observedRating is an observed cell of the preference matrix.
+type observedRating struct {
+	item_n int
+	user_n int
+	value  float64
+}
+
+// MatrixFactorizationStrategy is the biased matrix factorization (FunkSVD)
+// r_ui = μ + b_u + b_i + p_u·q_i learned with SGD on the observed ratings.
+// It does not compare users or items, so T only selects the engine it is
+// used with.
+type MatrixFactorizationStrategy[T Key] struct {
+	MatrixFactorizationConfig
+
+	lazyModel[factorModel]
+}
+
+// NewMatrixFactorizationStrategy defaults to 20 factors, learning rate 0.005,
+// regularization 0.02 and 20 epochs.
+func NewMatrixFactorizationStrategy[T Key](options ...MatrixFactorizationOption) *MatrixFactorizationStrategy[T] {
+
+	config := MatrixFactorizationConfig{
+		FactorConfig: FactorConfig{
+			Factors:        20,
+			Regularization: 0.02,
+			Epochs:         20,
+			InitStdDev:     0.1,
+			Seed:           1,
+		},
+		LearningRate: 0.005,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return &MatrixFactorizationStrategy[T]{MatrixFactorizationConfig: config}
+}
+
+// Fit learns the model from the ratings of the preference matrix, the same
+// Seed giving the same model.
+func (s *MatrixFactorizationStrategy[T]) Fit(recEngine *RecEngine[T]) error {
+
+	preferenceMatrix := recEngine.PreferenceMatrix
+	items_n, users_n := preferenceMatrix.RowsN(), preferenceMatrix.ColsN()
+
+	ratings := make([]observedRating, 0, preferenceMatrix.Nnz())
+	sum := 0.0
+
+	for item_n := range items_n {
+
+		indices, values := preferenceMatrix.GetSparseRow(item_n)
+
+		for k, user_n := range indices {
+
+			if values[k] != 0 {
+				ratings = append(ratings, observedRating{item_n: item_n, user_n: user_n, value: values[k]})
+				sum += values[k]
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(s.Seed))
+
+	model := &factorModel{
+		userBias:    make([]float64, users_n),
+		itemBias:    make([]float64, items_n),
+		userFactors: randomFactors(rng, users_n, s.Factors, s.InitStdDev),
+		itemFactors: randomFactors(rng, items_n, s.Factors, s.InitStdDev),
+	}
+
+	if len(ratings) > 0 {
+		model.globalMean = sum / float64(len(ratings))
+	}
+
+	lr, reg := s.LearningRate, s.Regularization
+
+	for range s.Epochs {
+
+		rng.Shuffle(len(ratings), func(i, j int) {
+			ratings[i], ratings[j] = ratings[j], ratings[i]
+		})
+
+		for _, r := range ratings {
+
+			residual := r.value - model.predict(r.user_n, r.item_n)
+
+			model.userBias[r.user_n] += lr * (residual - reg*model.userBias[r.user_n])
+			model.itemBias[r.item_n] += lr * (residual - reg*model.itemBias[r.item_n])
+
+			p, q := model.userFactors[r.user_n], model.itemFactors[r.item_n]
+
+			for f := range p {
+
+				p_f := p[f]
+				p[f] += lr * (residual*q[f] - reg*p_f)
+				q[f] += lr * (residual*p_f - reg*q[f])
+			}
+		}
+	}
+
+	s.store(model)
+
+	return nil
+}
+
+func randomFactors(rng *rand.Rand, n int, factors int, std_dev float64) [][]float64 {
+
+	matrix := make([][]float64, n)
+
+	for i := range n {
+
+		matrix[i] = make([]float64, factors)
+
+		for f := range factors {
+			matrix[i][f] = rng.NormFloat64() * std_dev
+		}
+	}
+
+	return matrix
+}
+
+// PredictRating returns μ + b_u + b_i + p_u·q_i, NaN when the model could not
+// be learned.
+func (s *MatrixFactorizationStrategy[T]) PredictRating(recEngine *RecEngine[T], target_user User, target_item Item, explanation *Explanation) float64 {
+
+	model, err := s.loadOrFit(func() error { return s.Fit(recEngine) })
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("model could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	user_n := recEngine.PreferenceMatrix.ColKeyToIndex[target_user]
+	item_n := recEngine.PreferenceMatrix.RowKeyToIndex[target_item]
+
+	explanation.explainFactors(model, user_n, item_n)
+
+	return model.predict(user_n, item_n)
+}
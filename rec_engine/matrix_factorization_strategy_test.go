@@ -0,0 +1,92 @@
+package rec_engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMatrixFactorizationSeedIsReproducible(t *testing.T) {
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(1)), 20, 15, 0.4)
+
+	fit := func() *RecEngine[User] {
+
+		recEngine := NewRecEngine[User](preferenceMatrix, NewMatrixFactorizationStrategy[User](WithSeed(7), WithEpochs(5)))
+
+		if err := recEngine.Fit(); err != nil {
+			t.Fatal(err)
+		}
+		return recEngine
+	}
+
+	a, b := fit(), fit()
+
+	for _, item := range preferenceMatrix.RowKeys {
+		for _, user := range preferenceMatrix.ColKeys {
+
+			prediction_a, _ := a.PredictRating(user, item)
+			prediction_b, _ := b.PredictRating(user, item)
+
+			if prediction_a != prediction_b {
+				t.Fatalf("(%v, %v): %v and %v with the same seed", user, item, prediction_a, prediction_b)
+			}
+		}
+	}
+
+	other := NewRecEngine[User](preferenceMatrix, NewMatrixFactorizationStrategy[User](WithSeed(8), WithEpochs(5)))
+
+	if err := other.Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	user, item := preferenceMatrix.ColKeys[0], preferenceMatrix.RowKeys[0]
+	prediction_a, _ := a.PredictRating(user, item)
+	prediction_other, _ := other.PredictRating(user, item)
+
+	if prediction_a == prediction_other {
+		t.Fatal("a different seed gave the same prediction")
+	}
+}
+
+func TestMatrixFactorizationTrainingErrorDrops(t *testing.T) {
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(2)), 20, 15, 0.4)
+
+	trainingRMSE := func(epochs int) float64 {
+
+		recEngine := NewRecEngine[User](preferenceMatrix, NewMatrixFactorizationStrategy[User](WithEpochs(epochs), WithLearningRate(0.01)))
+
+		if err := recEngine.Fit(); err != nil {
+			t.Fatal(err)
+		}
+
+		sum, n := 0.0, 0
+
+		for item_n, item := range preferenceMatrix.RowKeys {
+
+			indices, values := preferenceMatrix.GetSparseRow(item_n)
+
+			for k, user_n := range indices {
+
+				prediction, _ := recEngine.PredictRating(preferenceMatrix.ColKeys[user_n], item)
+				sum += (prediction - values[k]) * (prediction - values[k])
+				n++
+			}
+		}
+
+		return math.Sqrt(sum / float64(n))
+	}
+
+	previous := math.Inf(1)
+
+	for _, epochs := range []int{1, 10, 50, 200} {
+
+		rmse := trainingRMSE(epochs)
+
+		if rmse >= previous {
+			t.Fatalf("training RMSE after %d epochs is %.4f, not below %.4f", epochs, rmse, previous)
+		}
+		previous = rmse
+	}
+}
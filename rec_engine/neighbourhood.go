@@ -0,0 +1,232 @@
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/utils"
+)
+
+type NeighbourhoodConfig struct {
+	SimilarityConfig
+
+	// amount of the most similar neighbours used, 0 means all of them
+	TopK int
+	// neighbours less similar than this are ignored
+	MinSimilarity float64
+	// with fewer neighbours the prediction falls back to the baseline
+	MinNeighbours int
+	// look for neighbours only among those who rated the target item
+	// (user-based) or were rated by the target user (item-based); otherwise
+	// the top-k is taken among all and the ones without a rating are dropped
+	RatedOnly bool
+
+	// amount of the most similar neighbours kept per row by the fitted
+	// similarity model, 0 keeps all of them
+	ModelTopK int
+
+	// when set, the neighbours predict the deviation from the baseline b_ui
+	// (KNNBaseline), which is also the prediction without enough neighbours
+	Baseline *BaselineConfig
+}
+
+type NeighbourhoodOption func(*NeighbourhoodConfig)
+
+func WithSimilarity(similarity utils.SparseSimilarity) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.Similarity = similarity }
+}
+
+// WithAdjustedCosine compares with utils.SparseAdjustedCosine, the means
+// being computed from the preference matrix on every fit.
+func WithAdjustedCosine() NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.AdjustedCosine = true }
+}
+
+func WithSetSimilarity(similarity utils.SetSimilarity) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.SetSimilarity = similarity }
+}
+
+func WithSignificanceWeighting(cutoff int) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.SignificanceCutoff = cutoff }
+}
+
+func WithShrinkage(lambda float64) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.Shrinkage = lambda }
+}
+
+func WithTopK(k int) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.TopK = k }
+}
+
+func WithMinSimilarity(similarity float64) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.MinSimilarity = similarity }
+}
+
+func WithMinNeighbours(n int) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.MinNeighbours = n }
+}
+
+func WithRatedOnly(rated_only bool) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.RatedOnly = rated_only }
+}
+
+func WithModelTopK(k int) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) { c.ModelTopK = k }
+}
+
+func WithBaseline(options ...BaselineOption) NeighbourhoodOption {
+	return func(c *NeighbourhoodConfig) {
+		config := newBaselineConfig(options)
+		c.Baseline = &config
+	}
+}
+
+func newNeighbourhoodConfig(min_similarity float64, options []NeighbourhoodOption) NeighbourhoodConfig {
+
+	config := NeighbourhoodConfig{MinSimilarity: min_similarity, MinNeighbours: 1, RatedOnly: true}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
+
+type neighbour[K Key] struct {
+	Key        K
+	Similarity float64
+}
+
+func (n neighbour[K]) String() string {
+	return fmt.Sprint(n.Key)
+}
+
+// selectNeighbours picks the neighbours of target out of keys, similarities
+// holding the similarity of target to each key, and keeps only those for
+// which rated is true. The order of keys is kept unless TopK is set.
+func selectNeighbours[K Key](config NeighbourhoodConfig, keys []K, similarities []float64, target K, rated func(K) bool) []neighbour[K] {
+
+	candidates := []neighbour[K]{}
+
+	for i, key := range keys {
+
+		if key == target || math.IsNaN(similarities[i]) || similarities[i] < config.MinSimilarity {
+			continue
+		}
+
+		if config.RatedOnly && !rated(key) {
+			continue
+		}
+
+		candidates = append(candidates, neighbour[K]{Key: key, Similarity: similarities[i]})
+	}
+
+	if config.TopK > 0 && len(candidates) > config.TopK {
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Similarity > candidates[j].Similarity
+		})
+		candidates = candidates[:config.TopK]
+	}
+
+	neighbours := candidates[:0]
+
+	for _, n := range candidates {
+
+		if rated(n.Key) {
+			neighbours = append(neighbours, n)
+		}
+	}
+
+	return neighbours
+}
+
+func (c NeighbourhoodConfig) enoughNeighbours(n int) bool {
+	return n >= max(c.MinNeighbours, 1)
+}
+
+// fallbackReason tells why the n neighbours found were not used for a
+// prediction, rated telling how they were chosen, e.g. "rated the item".
+func (c NeighbourhoodConfig) fallbackReason(n int, rated string) string {
+
+	if n == 0 {
+		return "no neighbours " + rated
+	}
+
+	if !c.enoughNeighbours(n) {
+		return fmt.Sprintf("only %d neighbours %s, %d needed", n, rated, c.MinNeighbours)
+	}
+
+	return fmt.Sprintf("the similarities of the %d neighbours sum to 0", n)
+}
+
+// truncateSimilarityMatrix keeps the k largest similarities of every row in a
+// sparse matrix, so the result is in general not symmetric.
+func truncateSimilarityMatrix[K Key](similarityMatrix *KeyedMatrix[float64, K, K], k int) *KeyedMatrix[float64, K, K] {
+
+	n := similarityMatrix.RowsN()
+	truncated := NewCoordinateList[float64](n, n)
+
+	for row_n := range n {
+
+		indices, values := topSimilarities(similarityMatrix, row_n, k)
+
+		for i, col_n := range indices {
+			truncated.Append(row_n, col_n, values[i])
+		}
+	}
+
+	csr := truncated.ToCSR()
+	result, _ := NewKeyedMatrix[float64](&csr, similarityMatrix.RowKeys, similarityMatrix.ColKeys)
+
+	return result
+}
+
+// retruncateRow replaces row row_n of truncated with the k largest
+// similarities of the same row of similarityMatrix.
+func retruncateRow[K Key](truncated *KeyedMatrix[float64, K, K], similarityMatrix *KeyedMatrix[float64, K, K], row_n int, k int) {
+
+	old_indices, _ := truncated.GetSparseRow(row_n)
+
+	for _, col_n := range slices.Clone(old_indices) {
+		truncated.Set(row_n, col_n, 0)
+	}
+
+	indices, values := topSimilarities(similarityMatrix, row_n, k)
+
+	for i, col_n := range indices {
+		truncated.Set(row_n, col_n, values[i])
+	}
+}
+
+// topSimilarities returns the k largest similarities of row row_n except
+// the diagonal and NaN, ties being kept in column order.
+func topSimilarities[K Key](similarityMatrix *KeyedMatrix[float64, K, K], row_n int, k int) ([]int, []float64) {
+
+	indices, values := similarityMatrix.GetSparseRow(row_n)
+	order := make([]int, 0, len(indices))
+
+	for i, col_n := range indices {
+
+		if col_n != row_n && !math.IsNaN(values[i]) {
+			order = append(order, i)
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return values[order[i]] > values[order[j]]
+	})
+
+	top_indices := make([]int, 0, min(k, len(order)))
+	top_values := make([]float64, 0, min(k, len(order)))
+
+	for _, i := range order[:min(k, len(order))] {
+		top_indices = append(top_indices, indices[i])
+		top_values = append(top_values, values[i])
+	}
+
+	return top_indices, top_values
+}
@@ -0,0 +1,108 @@
+package rec_engine
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+func neighbourKeys[K Key](neighbours []neighbour[K]) []K {
+
+	keys := make([]K, len(neighbours))
+
+	for i, n := range neighbours {
+		keys[i] = n.Key
+	}
+
+	return keys
+}
+
+func TestSelectNeighbours(t *testing.T) {
+
+	keys := []Item{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}, {Id: 5}, {Id: 6}}
+	similarities := []float64{0.9, math.NaN(), 0.3, 0.95, 0.7, 0.5}
+	target := Item{Id: 3}
+
+	// the target user didn't rate item 4
+	rated := func(it Item) bool { return it.Id != 4 }
+
+	for _, test := range []struct {
+		name    string
+		options []NeighbourhoodOption
+		want    []int
+	}{
+		{"min similarity", []NeighbourhoodOption{WithMinSimilarity(0.6)}, []int{1, 5}},
+		{"no floor", []NeighbourhoodOption{WithMinSimilarity(-1)}, []int{1, 5, 6}},
+		{"top-k", []NeighbourhoodOption{WithMinSimilarity(0), WithTopK(2)}, []int{1, 5}},
+		// the top 2 are picked before the unrated item 4 is dropped
+		{"top-k among all", []NeighbourhoodOption{WithMinSimilarity(0), WithTopK(2), WithRatedOnly(false)}, []int{1}},
+		{"top-k larger than candidates", []NeighbourhoodOption{WithMinSimilarity(0), WithTopK(10)}, []int{1, 5, 6}},
+	} {
+
+		config := newNeighbourhoodConfig(0, test.options)
+		got := neighbourKeys(selectNeighbours(config, keys, similarities, target, rated))
+
+		if len(got) != len(test.want) {
+			t.Fatalf("%s: neighbours %v, want %v", test.name, got, test.want)
+		}
+
+		for i, it := range got {
+			if it.Id != test.want[i] {
+				t.Fatalf("%s: neighbours %v, want %v", test.name, got, test.want)
+			}
+		}
+	}
+}
+
+func TestMinNeighboursFallsBack(t *testing.T) {
+
+	items := []Item{{Id: 1}, {Id: 2}, {Id: 3}}
+	users := []User{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}}
+
+	// U1 hasn't rated P3, which U2 and U3 rated
+	preferenceMatrix, err := NewKeyedMatrix[float64](NewMatrix([][]float64{
+		{5, 4, 1, 2},
+		{4, 5, 2, 1},
+		{0, 4, 2, 0},
+	}), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target_user, target_item := users[0], items[2]
+
+	for _, test := range []struct {
+		min_neighbours int
+		fallback       string
+	}{
+		{2, ""},
+		{3, "only 2 neighbours rated the item, 3 needed"},
+	} {
+
+		recEngine := NewRecEngine[User](preferenceMatrix, NewUserBasedStrategy(WithMinSimilarity(-1), WithMinNeighbours(test.min_neighbours)))
+		explanation, err := recEngine.ExplainRating(target_user, target_item)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(explanation.Fallback, test.fallback) || (test.fallback == "") != (explanation.Fallback == "") {
+			t.Fatalf("min %d neighbours: fallback %q, want %q", test.min_neighbours, explanation.Fallback, test.fallback)
+		}
+
+		if test.fallback == "" {
+
+			if len(explanation.Neighbours) != 2 {
+				t.Fatalf("neighbours %v, want U2 and U3", explanation.Neighbours)
+			}
+			continue
+		}
+
+		if want := recEngine.baselineRating(target_user, target_item); explanation.Rating != want {
+			t.Fatalf("rating %v, want the baseline %v", explanation.Rating, want)
+		}
+	}
+}
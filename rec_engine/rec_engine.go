@@ -1,206 +1,420 @@
-package rec_engine
-
-import (
-	"fmt"
-	"sort"
-
-	. "github.com/PetrDoroshev/RS/matrix"
-)
-
-type User struct {
-	Id int
-}
-
-func (u User) String() string {
-	return fmt.Sprintf("U%d", u.Id)
-}
-
-type Item struct {
-	Id   int
-	Name string
-}
-
-func (it Item) String() string {
-	return fmt.Sprintf("P%d", it.Id)
-}
-
-type ItemRating struct {
-	Item   Item
-	Rating float64
-}
-
-type Key interface {
-	User | Item
-}
-
-type similarityStrategy[T Key] interface {
-	BuildSimilarityMatrix(objects_to_comp []T, preferenceMatrix *KeyedMatrix[float64, Item, User]) *KeyedMatrix[float64, T, T]
-	PredictRating(recEngine *RecEngine[T], target_user User, target_item Item, output bool) float64
-}
-
-type RecEngine[T Key] struct {
-	PreferenceMatrix KeyedMatrix[float64, Item, User]
-	Strategy         similarityStrategy[T]
-}
-
-func NewRecEngine[T Key](preferenceMatrix KeyedMatrix[float64, Item, User], strategy similarityStrategy[T]) *RecEngine[T] {
-
-	return &RecEngine[T]{PreferenceMatrix: preferenceMatrix, Strategy: strategy}
-}
-
-func (re *RecEngine[T]) AvgItemRating(item Item) float64 {
-
-	sum := 0.0
-	n := 0
-
-	item_index := re.PreferenceMatrix.RowKeyToIndex[item]
-
-	for col_n := range re.PreferenceMatrix.ColsN() {
-
-		rating := re.PreferenceMatrix.Get(item_index, col_n)
-		if rating > 0 {
-			sum += rating
-			n++
-		}
-	}
-
-	return sum / float64(n)
-
-}
-
-func (re *RecEngine[T]) AvgUserRating(user User) float64 {
-
-	n := 0
-	sum := 0.0
-
-	user_index := re.PreferenceMatrix.ColKeyToIndex[user]
-
-	for row_n := range re.PreferenceMatrix.RowsN() {
-
-		rating := re.PreferenceMatrix.Get(row_n, user_index)
-
-		if rating != 0 {
-			sum += rating
-			n++
-		}
-	}
-
-	if n == 0 {
-		return 0.0
-	}
-
-	return sum / float64(n)
-
-}
-
-func (re *RecEngine[T]) PredictRating(target_user User, target_item Item, output bool) float64 {
-
-	return re.Strategy.PredictRating(re, target_user, target_item, output)
-}
-
-func (re *RecEngine[T]) getItemPredictedRatings(user User) []ItemRating {
-
-	recommendations := make([]ItemRating, 0, re.PreferenceMatrix.RowsN())
-
-	for _, item := range re.PreferenceMatrix.RowKeys {
-
-		rating := re.PreferenceMatrix.GetByKey(item, user)
-
-		if rating == 0 {
-
-			predicted_rating := re.PredictRating(user, item, false)
-			recommendations = append(recommendations, ItemRating{Item: item, Rating: predicted_rating})
-		}
-	}
-
-	return recommendations
-}
-
-func (re *RecEngine[T]) MakeRecommendationTHD(user User, threshold float64) []ItemRating {
-
-	var recommendations []ItemRating
-
-	if re.AvgUserRating(user) == 0 {
-
-		for _, item := range re.PreferenceMatrix.RowKeys {
-			recommendations = append(recommendations, ItemRating{Item: item, Rating: re.AvgItemRating(item)})
-		}
-
-	} else {
-		recommendations = re.getItemPredictedRatings(user)
-	}
-
-	sort.Slice(recommendations, func(i, j int) bool {
-		return recommendations[i].Rating > recommendations[j].Rating
-	})
-
-	n := 0
-
-	for n < len(recommendations) && recommendations[n].Rating >= threshold {
-		n++
-	}
-
-	return recommendations[:n]
-
-}
-
-func (re *RecEngine[T]) MakeRecommendationTopN(user User, N int) []ItemRating {
-
-	var recommendations []ItemRating
-
-	if re.AvgUserRating(user) == 0 {
-
-		for _, item := range re.PreferenceMatrix.RowKeys {
-			recommendations = append(recommendations, ItemRating{Item: item, Rating: re.AvgItemRating(item)})
-		}
-
-	} else {
-		recommendations = re.getItemPredictedRatings(user)
-	}
-
-	sort.Slice(recommendations, func(i, j int) bool {
-		return recommendations[i].Rating > recommendations[j].Rating
-	})
-
-	return recommendations[:min(N, len(recommendations))]
-}
-
-func PrintPreferenceMatrix[T Numeric](preferenceMatrix *KeyedMatrix[T, Item, User]) {
-
-	fmt.Print("\t")
-	for col_index := range preferenceMatrix.ColsN() {
-		fmt.Printf("U%d\t", preferenceMatrix.ColKeys[col_index].Id)
-	}
-
-	fmt.Print("\n")
-
-	for row_index := range preferenceMatrix.RowsN() {
-
-		fmt.Printf("P%d\t", preferenceMatrix.RowKeys[row_index].Id)
-
-		for col_index := range preferenceMatrix.ColsN() {
-			fmt.Printf("%.2v\t", preferenceMatrix.Get(row_index, col_index))
-		}
-		fmt.Println()
-	}
-}
-
-func PrintSimilarityMatrix[T Numeric, K Key](similarityMatrix *KeyedMatrix[T, K, K]) {
-
-	fmt.Print("\t")
-	for col_index := range similarityMatrix.ColsN() {
-		fmt.Printf("%v\t", similarityMatrix.ColKeys[col_index])
-	}
-
-	fmt.Print("\n")
-
-	for row_index := range similarityMatrix.RowsN() {
-
-		fmt.Printf("%v\t", similarityMatrix.RowKeys[row_index])
-
-		for col_index := range similarityMatrix.ColsN() {
-			fmt.Printf("%.2v\t", similarityMatrix.Get(row_index, col_index))
-		}
-		fmt.Println()
-	}
-}
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+type User struct {
+	Id int
+}
+
+func (u User) String() string {
+	return fmt.Sprintf("U%d", u.Id)
+}
+
+type Item struct {
+	Id   int
+	Name string
+}
+
+func (it Item) String() string {
+	return fmt.Sprintf("P%d", it.Id)
+}
+
+type ItemRating struct {
+	Item   Item
+	Rating float64
+}
+
+type Key interface {
+	User | Item
+}
+
+// PredictionStrategy predicts ratings for a RecEngine[T]. When explanation
+// is not nil the strategy fills in how it arrived at the rating.
+type PredictionStrategy[T Key] interface {
+	PredictRating(recEngine *RecEngine[T], target_user User, target_item Item, explanation *Explanation) float64
+}
+
+// similarityStrategy is a neighbourhood strategy comparing objects of type T.
+type similarityStrategy[T Key] interface {
+	PredictionStrategy[T]
+	BuildSimilarityMatrix(objects_to_comp []T, preferenceMatrix *KeyedMatrix[float64, Item, User]) (*KeyedMatrix[float64, T, T], error)
+}
+
+var (
+	_ similarityStrategy[User] = (*UserBasedStrategy)(nil)
+	_ similarityStrategy[Item] = (*ItemBasedStrategy)(nil)
+	_ fittable[User]           = (*MatrixFactorizationStrategy[User])(nil)
+	_ fittable[User]           = (*ImplicitALSStrategy[User])(nil)
+	_ fittable[User]           = (*SVDStrategy[User])(nil)
+	_ updatable[Item]          = (*SlopeOneStrategy)(nil)
+	_ fittable[User]           = (*BaselineStrategy[User])(nil)
+)
+
+// fittable strategies keep a model built from the preference matrix, which
+// has to be invalidated when the matrix changes.
+type fittable[T Key] interface {
+	Fit(recEngine *RecEngine[T]) error
+	Invalidate()
+}
+
+// updatable strategies apply a changed rating to their model themselves
+// instead of being invalidated.
+type updatable[T Key] interface {
+	UpdateRating(recEngine *RecEngine[T], user User, item Item, old_rating float64, new_rating float64)
+}
+
+type RecEngine[T Key] struct {
+	PreferenceMatrix *KeyedMatrix[float64, Item, User]
+	Strategy         PredictionStrategy[T]
+}
+
+// NewRecEngine doesn't copy preferenceMatrix: ratings, users and items added
+// through the engine are seen by every holder of the pointer.
+func NewRecEngine[T Key](preferenceMatrix *KeyedMatrix[float64, Item, User], strategy PredictionStrategy[T]) *RecEngine[T] {
+
+	return &RecEngine[T]{PreferenceMatrix: preferenceMatrix, Strategy: strategy}
+}
+
+// Fit builds the model of the strategy, if it has one, from the current
+// preference matrix.
+func (re *RecEngine[T]) Fit() error {
+
+	if strategy, ok := re.Strategy.(fittable[T]); ok {
+		return strategy.Fit(re)
+	}
+
+	return nil
+}
+
+// Invalidate has to be called after PreferenceMatrix is modified directly, so
+// that the model of the strategy is rebuilt.
+func (re *RecEngine[T]) Invalidate() {
+
+	if strategy, ok := re.Strategy.(fittable[T]); ok {
+		strategy.Invalidate()
+	}
+}
+
+// AddRating sets the rating of item by user in PreferenceMatrix and updates
+// or invalidates the model of the strategy.
+func (re *RecEngine[T]) AddRating(user User, item Item, rating float64) error {
+
+	if err := re.checkKeys(user, item); err != nil {
+		return err
+	}
+
+	old_rating, _ := re.PreferenceMatrix.LookupByKey(item, user)
+
+	if old_rating == rating {
+		return nil
+	}
+
+	if err := re.PreferenceMatrix.SetByKey(item, user, rating); err != nil {
+		return err
+	}
+
+	if strategy, ok := re.Strategy.(updatable[T]); ok {
+		strategy.UpdateRating(re, user, item, old_rating, rating)
+	} else {
+		re.Invalidate()
+	}
+
+	return nil
+}
+
+func (re *RecEngine[T]) RemoveRating(user User, item Item) error {
+	return re.AddRating(user, item, 0)
+}
+
+// AddUser adds a user without ratings, e.g. a new sign-up.
+func (re *RecEngine[T]) AddUser(user User) error {
+
+	if err := re.PreferenceMatrix.AddColKey(user); err != nil {
+		return err
+	}
+	re.Invalidate()
+
+	return nil
+}
+
+// AddItem adds an item without ratings, e.g. a new catalogue entry.
+func (re *RecEngine[T]) AddItem(item Item) error {
+
+	if err := re.PreferenceMatrix.AddRowKey(item); err != nil {
+		return err
+	}
+	re.Invalidate()
+
+	return nil
+}
+
+func (re *RecEngine[T]) DeleteUser(user User) error {
+
+	if err := re.PreferenceMatrix.DeleteColByKey(user); err != nil {
+		return err
+	}
+	re.Invalidate()
+
+	return nil
+}
+
+func (re *RecEngine[T]) DeleteItem(item Item) error {
+
+	if err := re.PreferenceMatrix.DeleteRowByKey(item); err != nil {
+		return err
+	}
+	re.Invalidate()
+
+	return nil
+}
+
+func (re *RecEngine[T]) AvgItemRating(item Item) float64 {
+
+	sum := 0.0
+	n := 0
+
+	_, ratings := re.PreferenceMatrix.GetSparseRowByKey(item)
+
+	for _, rating := range ratings {
+
+		if rating > 0 {
+			sum += rating
+			n++
+		}
+	}
+
+	if n == 0 {
+		return 0.0
+	}
+
+	return sum / float64(n)
+
+}
+
+func (re *RecEngine[T]) AvgUserRating(user User) float64 {
+
+	n := 0
+	sum := 0.0
+
+	_, ratings := re.PreferenceMatrix.GetSparseColByKey(user)
+
+	for _, rating := range ratings {
+
+		if rating != 0 {
+			sum += rating
+			n++
+		}
+	}
+
+	if n == 0 {
+		return 0.0
+	}
+
+	return sum / float64(n)
+
+}
+
+// baselineRating is the fallback prediction when a strategy has not enough
+// neighbours: the user's mean rating, or the item's one for users without
+// ratings.
+func (re *RecEngine[T]) baselineRating(user User, item Item) float64 {
+
+	if avg := re.AvgUserRating(user); avg != 0 {
+		return avg
+	}
+
+	return re.AvgItemRating(item)
+}
+
+// explainedBaselineRating returns baselineRating, recording in explanation
+// which mean rating it falls back to and why.
+func (re *RecEngine[T]) explainedBaselineRating(user User, item Item, explanation *Explanation, reason string) float64 {
+
+	rating := re.baselineRating(user, item)
+
+	if re.AvgUserRating(user) != 0 {
+		explanation.fallbackTo(reason, "user's mean rating", rating)
+	} else {
+		explanation.fallbackTo(reason, "item's mean rating", rating)
+	}
+
+	return rating
+}
+
+// checkKeys returns an error wrapping ErrUnknownColKey or ErrUnknownRowKey
+// when the user or the item is not in PreferenceMatrix.
+func (re *RecEngine[T]) checkKeys(user User, item Item) error {
+
+	if _, err := re.PreferenceMatrix.ColIndex(user); err != nil {
+		return err
+	}
+
+	_, err := re.PreferenceMatrix.RowIndex(item)
+
+	return err
+}
+
+func (re *RecEngine[T]) PredictRating(target_user User, target_item Item) (float64, error) {
+
+	if err := re.checkKeys(target_user, target_item); err != nil {
+		return math.NaN(), err
+	}
+
+	return re.Strategy.PredictRating(re, target_user, target_item, nil), nil
+}
+
+// ExplainRating predicts the rating like PredictRating and tells which
+// neighbours or model terms it came from.
+func (re *RecEngine[T]) ExplainRating(target_user User, target_item Item) (*Explanation, error) {
+
+	if err := re.checkKeys(target_user, target_item); err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{User: target_user, Item: target_item, Strategy: strategyName(re.Strategy)}
+	explanation.Rating = re.Strategy.PredictRating(re, target_user, target_item, explanation)
+
+	return explanation, nil
+}
+
+// strategyName returns the type name of strategy without the package and
+// type parameters.
+func strategyName(strategy any) string {
+
+	name := fmt.Sprintf("%T", strategy)
+
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// getItemPredictedRatings predicts the ratings of the items the user has not
+// rated, leaving out the ones the strategy can't predict (NaN) so that they
+// don't break the sort.
+func (re *RecEngine[T]) getItemPredictedRatings(user User) []ItemRating {
+
+	recommendations := make([]ItemRating, 0, re.PreferenceMatrix.RowsN())
+
+	for _, item := range re.PreferenceMatrix.RowKeys {
+
+		rating, _ := re.PreferenceMatrix.LookupByKey(item, user)
+
+		if rating == 0 {
+
+			predicted_rating := re.Strategy.PredictRating(re, user, item, nil)
+
+			if math.IsNaN(predicted_rating) {
+				continue
+			}
+			recommendations = append(recommendations, ItemRating{Item: item, Rating: predicted_rating})
+		}
+	}
+
+	return recommendations
+}
+
+func (re *RecEngine[T]) MakeRecommendationTHD(user User, threshold float64) ([]ItemRating, error) {
+
+	if _, err := re.PreferenceMatrix.ColIndex(user); err != nil {
+		return nil, err
+	}
+
+	var recommendations []ItemRating
+
+	if re.AvgUserRating(user) == 0 {
+
+		for _, item := range re.PreferenceMatrix.RowKeys {
+			recommendations = append(recommendations, ItemRating{Item: item, Rating: re.AvgItemRating(item)})
+		}
+
+	} else {
+		recommendations = re.getItemPredictedRatings(user)
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Rating > recommendations[j].Rating
+	})
+
+	n := 0
+
+	for n < len(recommendations) && recommendations[n].Rating >= threshold {
+		n++
+	}
+
+	return recommendations[:n], nil
+
+}
+
+func (re *RecEngine[T]) MakeRecommendationTopN(user User, N int) ([]ItemRating, error) {
+
+	if _, err := re.PreferenceMatrix.ColIndex(user); err != nil {
+		return nil, err
+	}
+
+	var recommendations []ItemRating
+
+	if re.AvgUserRating(user) == 0 {
+
+		for _, item := range re.PreferenceMatrix.RowKeys {
+			recommendations = append(recommendations, ItemRating{Item: item, Rating: re.AvgItemRating(item)})
+		}
+
+	} else {
+		recommendations = re.getItemPredictedRatings(user)
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Rating > recommendations[j].Rating
+	})
+
+	return recommendations[:min(N, len(recommendations))], nil
+}
+
+func PrintPreferenceMatrix[T Numeric](preferenceMatrix *KeyedMatrix[T, Item, User]) {
+
+	fmt.Print("\t")
+	for col_index := range preferenceMatrix.ColsN() {
+		fmt.Printf("U%d\t", preferenceMatrix.ColKeys[col_index].Id)
+	}
+
+	fmt.Print("\n")
+
+	for row_index := range preferenceMatrix.RowsN() {
+
+		fmt.Printf("P%d\t", preferenceMatrix.RowKeys[row_index].Id)
+
+		for col_index := range preferenceMatrix.ColsN() {
+			fmt.Printf("%.2v\t", preferenceMatrix.Get(row_index, col_index))
+		}
+		fmt.Println()
+	}
+}
+
+func PrintSimilarityMatrix[T Numeric, K Key](similarityMatrix *KeyedMatrix[T, K, K]) {
+
+	fmt.Print("\t")
+	for col_index := range similarityMatrix.ColsN() {
+		fmt.Printf("%v\t", similarityMatrix.ColKeys[col_index])
+	}
+
+	fmt.Print("\n")
+
+	for row_index := range similarityMatrix.RowsN() {
+
+		fmt.Printf("%v\t", similarityMatrix.RowKeys[row_index])
+
+		for col_index := range similarityMatrix.ColsN() {
+			fmt.Printf("%.2v\t", similarityMatrix.Get(row_index, col_index))
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,123 @@
+package rec_engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// oddNaNStrategy can't predict the items with an odd id.
+type oddNaNStrategy struct{}
+
+func (oddNaNStrategy) PredictRating(recEngine *RecEngine[User], target_user User, target_item Item, explanation *Explanation) float64 {
+
+	if target_item.Id%2 == 1 {
+		return math.NaN()
+	}
+	return float64(target_item.Id)
+}
+
+func assertRanked(t *testing.T, recommendations []ItemRating) {
+
+	t.Helper()
+
+	for k, r := range recommendations {
+
+		if math.IsNaN(r.Rating) {
+			t.Fatalf("%v has a NaN rating", r.Item)
+		}
+
+		if k > 0 && r.Rating > recommendations[k-1].Rating {
+			t.Fatalf("recommendations aren't sorted: %v", recommendations)
+		}
+	}
+}
+
+func TestRecommendationsSkipNaN(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 10, 8, 0.5)
+
+	recEngine := NewRecEngine[User](preferenceMatrix, NewUserBasedStrategy())
+
+	item, user := Item{Id: 50}, User{Id: 50}
+
+	if err := recEngine.AddItem(item); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recEngine.AddUser(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if avg := recEngine.AvgItemRating(item); avg != 0 {
+		t.Fatalf("mean rating of an item without ratings = %v, want 0", avg)
+	}
+
+	for _, u := range recEngine.PreferenceMatrix.ColKeys {
+
+		recommendations, err := recEngine.MakeRecommendationTopN(u, recEngine.PreferenceMatrix.RowsN())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertRanked(t, recommendations)
+	}
+
+	recEngine.Strategy = oddNaNStrategy{}
+
+	recommendations, err := recEngine.MakeRecommendationTopN(recEngine.PreferenceMatrix.ColKeys[0], recEngine.PreferenceMatrix.RowsN())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertRanked(t, recommendations)
+
+	for _, r := range recommendations {
+		if r.Item.Id%2 == 1 {
+			t.Fatalf("%v can't be predicted but is recommended", r.Item)
+		}
+	}
+
+	recommendations, err = recEngine.MakeRecommendationTHD(recEngine.PreferenceMatrix.ColKeys[0], 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRanked(t, recommendations)
+}
+
+func TestEngineSharesPreferenceMatrix(t *testing.T) {
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(2)), 6, 5, 0.5)
+	recEngine := NewRecEngine[User](preferenceMatrix, NewUserBasedStrategy())
+
+	user, item := User{Id: 50}, Item{Id: 50}
+
+	if err := recEngine.AddUser(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recEngine.AddItem(item); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recEngine.DeleteUser(preferenceMatrix.ColKeys[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recEngine.AddRating(user, item, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, cols := preferenceMatrix.Matrix().Dims()
+
+	if len(preferenceMatrix.RowKeys) != rows || len(preferenceMatrix.ColKeys) != cols {
+		t.Fatalf("%d row and %d column keys for a %dx%d matrix", len(preferenceMatrix.RowKeys), len(preferenceMatrix.ColKeys), rows, cols)
+	}
+
+	if rating, ok := preferenceMatrix.LookupByKey(item, user); !ok || rating != 4 {
+		t.Fatalf("rating seen by the caller = %v, %v, want 4", rating, ok)
+	}
+}
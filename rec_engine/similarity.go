@@ -0,0 +1,150 @@
+package rec_engine
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/utils"
+)
+
+type SimilarityConfig struct {
+	// SparseCosSimilarity when nil
+	Similarity utils.SparseSimilarity
+	// takes precedence over Similarity: utils.SparseAdjustedCosine centering
+	// every entry by the mean rating of its user (item-based) or of its item
+	// (user-based), taken from the matrix the similarities are built from
+	AdjustedCosine bool
+	// takes precedence over the above, compares the sets of rated entries
+	SetSimilarity utils.SetSimilarity
+
+	// Herlocker significance weighting: similarities based on n < cutoff
+	// co-rated entries are scaled by n/cutoff, 0 disables it (50 is usual)
+	SignificanceCutoff int
+	// shrinkage towards 0 by n/(n+λ) for n co-rated entries, 0 disables it
+	Shrinkage float64
+}
+
+func (c SimilarityConfig) weight(similarity float64, co_rated int) float64 {
+
+	if c.SignificanceCutoff > 0 {
+		similarity *= float64(min(co_rated, c.SignificanceCutoff)) / float64(c.SignificanceCutoff)
+	}
+
+	if c.Shrinkage > 0 {
+		similarity *= float64(co_rated) / (float64(co_rated) + c.Shrinkage)
+	}
+
+	return similarity
+}
+
+// incremental tells whether the default cosine similarity is used, which the
+// similarity model updates in place.
+func (c SimilarityConfig) incremental() bool {
+	return c.Similarity == nil && !c.AdjustedCosine && c.SetSimilarity == nil
+}
+
+// buildSimilarityMatrix compares every pair of objects with SetSimilarity on
+// the indices of their rated entries when it is set, otherwise with
+// Similarity on their sparse rating vectors returned by line, and weights the
+// result by the amount of co-rated entries, which is returned as the second
+// matrix. means returns the mean rating at every position of the vectors for
+// AdjustedCosine. Pairs for which the similarity is undefined get NaN, other
+// errors of the similarity are returned.
+func buildSimilarityMatrix[K Key](objects_to_comp []K,
+	line func(K) ([]int, []float64),
+	means func() []float64,
+	config SimilarityConfig) (*KeyedMatrix[float64, K, K], *KeyedMatrix[int, K, K], error) {
+
+	similarityMatrix, _ := NewKeyedMatrix[float64](NewZeroMatrix[float64](len(objects_to_comp), len(objects_to_comp)),
+		objects_to_comp,
+		objects_to_comp,
+	)
+
+	coRatingMatrix, _ := NewKeyedMatrix[int](NewZeroMatrix[int](len(objects_to_comp), len(objects_to_comp)),
+		objects_to_comp,
+		objects_to_comp,
+	)
+
+	sets := make([][]int, len(objects_to_comp))
+	vectors := make([][]float64, len(objects_to_comp))
+
+	for i, object := range objects_to_comp {
+		sets[i], vectors[i] = line(object)
+	}
+
+	var compare func(i int, k int) (float64, error)
+
+	if config.SetSimilarity != nil {
+
+		compare = func(i int, k int) (float64, error) {
+			return config.SetSimilarity(sets[i], sets[k])
+		}
+
+	} else {
+
+		measure := config.Similarity
+
+		switch {
+		case config.AdjustedCosine:
+			measure = utils.SparseAdjustedCosine(means())
+		case measure == nil:
+			measure = utils.SparseCosSimilarity
+		}
+
+		compare = func(i int, k int) (float64, error) {
+			return measure(sets[i], vectors[i], sets[k], vectors[k])
+		}
+	}
+
+	for i := range objects_to_comp {
+
+		coRatingMatrix.Set(i, i, len(sets[i]))
+
+		for k := i + 1; k < len(objects_to_comp); k++ {
+
+			similarity, err := compare(i, k)
+
+			if err != nil && !errors.Is(err, utils.ErrUndefined) {
+				return nil, nil, fmt.Errorf("similarity of %v and %v: %w", objects_to_comp[i], objects_to_comp[k], err)
+			}
+
+			co_rated := utils.IntersectionSize(sets[i], sets[k])
+			similarity = config.weight(similarity, co_rated)
+
+			similarityMatrix.Set(i, k, similarity)
+			similarityMatrix.Set(k, i, similarity)
+
+			coRatingMatrix.Set(i, k, co_rated)
+			coRatingMatrix.Set(k, i, co_rated)
+		}
+	}
+
+	return similarityMatrix, coRatingMatrix, nil
+}
+
+// meanRatings returns the mean of the non-zero ratings of each of the n
+// lines, e.g. GetSparseCol for the mean rating of every user.
+func meanRatings(n int, line func(n int) ([]int, []float64)) []float64 {
+
+	means := make([]float64, n)
+
+	for i := range n {
+
+		_, ratings := line(i)
+		rated := 0
+
+		for _, rating := range ratings {
+			if rating != 0 {
+				means[i] += rating
+				rated++
+			}
+		}
+
+		if rated > 0 {
+			means[i] /= float64(rated)
+		}
+	}
+
+	return means
+}
@@ -0,0 +1,274 @@
+package rec_engine
+
+import (
+	"math"
+	"slices"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/utils"
+)
+
+// similarityModel is a fitted similarity matrix between all items or all
+// users of a preference matrix, the index of an object being its row or
+// column index there. For the default cosine similarity the model also keeps
+// the dot products of every pair, so a changed rating is applied without
+// comparing all the vectors again.
+type similarityModel[K Key] struct {
+	config NeighbourhoodConfig
+
+	similarityMatrix *KeyedMatrix[float64, K, K]
+	coRatingMatrix   *KeyedMatrix[int, K, K]
+	truncated        *KeyedMatrix[float64, K, K]
+
+	// dots[i][k] is the dot product of the rating vectors of objects i and k,
+	// the diagonal holding the squared norms; nil when not incremental
+	dots [][]float64
+}
+
+// fitSimilarityModel takes the matrices built by BuildSimilarityMatrixWithCounts
+// and line returning the sparse rating vector of the object with index n.
+func fitSimilarityModel[K Key](config NeighbourhoodConfig,
+	similarityMatrix *KeyedMatrix[float64, K, K],
+	coRatingMatrix *KeyedMatrix[int, K, K],
+	line func(n int) ([]int, []float64)) *similarityModel[K] {
+
+	model := &similarityModel[K]{
+		config:           config,
+		similarityMatrix: similarityMatrix,
+		coRatingMatrix:   coRatingMatrix,
+		truncated:        similarityMatrix,
+	}
+
+	if config.ModelTopK > 0 {
+		model.truncated = truncateSimilarityMatrix(similarityMatrix, config.ModelTopK)
+	}
+
+	if !config.incremental() {
+		return model
+	}
+
+	n := similarityMatrix.RowsN()
+	model.dots = make([][]float64, n)
+
+	indices := make([][]int, n)
+	values := make([][]float64, n)
+
+	for i := range n {
+		model.dots[i] = make([]float64, n)
+		indices[i], values[i] = line(i)
+	}
+
+	for i := range n {
+		for k := i; k < n; k++ {
+
+			dot := utils.SparseDotProduct(indices[i], values[i], indices[k], values[k])
+			model.dots[i][k] = dot
+			model.dots[k][i] = dot
+		}
+	}
+
+	return model
+}
+
+func (m *similarityModel[K]) matrix() *KeyedMatrix[float64, K, K] {
+	return m.truncated
+}
+
+func (m *similarityModel[K]) incremental() bool {
+	return m.dots != nil
+}
+
+// update applies the change of the rating of object n from old_rating to
+// new_rating. indices and values hold the ratings the other objects have at
+// the same position, e.g. the other ratings of the same user for items.
+func (m *similarityModel[K]) update(n int, indices []int, values []float64, old_rating float64, new_rating float64) {
+
+	co_rated_delta := 0
+
+	switch {
+	case old_rating == 0 && new_rating != 0:
+		co_rated_delta = 1
+	case old_rating != 0 && new_rating == 0:
+		co_rated_delta = -1
+	}
+
+	for k, other := range indices {
+
+		if other == n {
+			continue
+		}
+
+		m.dots[n][other] += (new_rating - old_rating) * values[k]
+		m.dots[other][n] = m.dots[n][other]
+
+		count := m.coRatingMatrix.Get(n, other) + co_rated_delta
+		m.coRatingMatrix.Set(n, other, count)
+		m.coRatingMatrix.Set(other, n, count)
+	}
+
+	m.dots[n][n] += new_rating*new_rating - old_rating*old_rating
+	m.coRatingMatrix.Set(n, n, m.coRatingMatrix.Get(n, n)+co_rated_delta)
+
+	for other := range m.dots {
+
+		if other == n {
+			continue
+		}
+
+		similarity := m.config.weight(m.cosine(n, other), m.coRatingMatrix.Get(n, other))
+
+		m.similarityMatrix.Set(n, other, similarity)
+		m.similarityMatrix.Set(other, n, similarity)
+
+		if m.config.ModelTopK > 0 && m.keeps(other, n, similarity) {
+			retruncateRow(m.truncated, m.similarityMatrix, other, m.config.ModelTopK)
+		}
+	}
+
+	if m.config.ModelTopK > 0 {
+		retruncateRow(m.truncated, m.similarityMatrix, n, m.config.ModelTopK)
+	}
+}
+
+// keeps tells whether the truncated row row_n may change when its
+// similarity to n becomes similarity: n is kept there, or would be now.
+func (m *similarityModel[K]) keeps(row_n int, n int, similarity float64) bool {
+
+	indices, values := m.truncated.GetSparseRow(row_n)
+
+	if slices.Contains(indices, n) {
+		return true
+	}
+
+	if math.IsNaN(similarity) {
+		return false
+	}
+
+	return len(indices) < m.config.ModelTopK || similarity >= slices.Min(values)
+}
+
+func (m *similarityModel[K]) cosine(i int, k int) float64 {
+
+	if m.coRatingMatrix.Get(i, i) == 0 || m.coRatingMatrix.Get(k, k) == 0 {
+		return math.NaN()
+	}
+
+	return m.dots[i][k] / math.Sqrt(m.dots[i][i]*m.dots[k][k])
+}
+
+// neighbourhoodModel is what UserBasedStrategy and ItemBasedStrategy fit: the
+// similarity model between the users or the items, K, and the baseline the
+// neighbours predict the deviations from when NeighbourhoodConfig.Baseline is
+// set.
+type neighbourhoodModel[K Key] struct {
+	model    lazyModel[similarityModel[K]]
+	baseline lazyModel[baselineModel]
+}
+
+// fit compares the objects keyed by keys with build, line returning the sparse
+// rating vector of the object with index n.
+func (f *neighbourhoodModel[K]) fit(config NeighbourhoodConfig,
+	preferenceMatrix *KeyedMatrix[float64, Item, User],
+	keys []K,
+	build func([]K, *KeyedMatrix[float64, Item, User]) (*KeyedMatrix[float64, K, K], *KeyedMatrix[int, K, K], error),
+	line func(n int) ([]int, []float64)) error {
+
+	var baseline *baselineModel
+
+	if config.Baseline != nil {
+
+		var err error
+
+		if baseline, err = fitBaseline(*config.Baseline, preferenceMatrix); err != nil {
+			return err
+		}
+	}
+
+	similarityMatrix, coRatingMatrix, err := build(keys, preferenceMatrix)
+
+	if err != nil {
+		return err
+	}
+
+	f.model.store(fitSimilarityModel(config, similarityMatrix, coRatingMatrix, line))
+	f.baseline.store(baseline)
+
+	return nil
+}
+
+// Invalidate drops the similarity model and the baseline, both are fitted
+// again on the next prediction.
+func (f *neighbourhoodModel[K]) Invalidate() {
+
+	f.model.Invalidate()
+	f.baseline.Invalidate()
+}
+
+// update applies a changed rating of the object with index n, see
+// similarityModel.update. Only the default cosine similarity is updated in
+// place, other models are invalidated.
+func (f *neighbourhoodModel[K]) update(n int,
+	indices []int,
+	values []float64,
+	old_rating float64,
+	new_rating float64) {
+
+	// the biases move with every rating, they are learned again on the next
+	// prediction rather than on every change
+	f.baseline.Invalidate()
+
+	f.model.update(func(model *similarityModel[K]) *similarityModel[K] {
+
+		if !model.incremental() {
+			return nil
+		}
+
+		model.update(n, indices, values, old_rating, new_rating)
+		return model
+	})
+}
+
+// SimilarityMatrix returns the fitted similarity matrix, truncated to
+// ModelTopK neighbours per object when it is set, nil before Fit.
+func (f *neighbourhoodModel[K]) SimilarityMatrix() *KeyedMatrix[float64, K, K] {
+
+	if model := f.model.load(); model != nil {
+		return model.matrix()
+	}
+
+	return nil
+}
+
+// fittedMatrix returns the similarity matrix, calling fit first when there is
+// none.
+func (f *neighbourhoodModel[K]) fittedMatrix(fit func() error) (*KeyedMatrix[float64, K, K], error) {
+
+	model, err := f.model.loadOrFit(fit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model.matrix(), nil
+}
+
+// fittedBaseline returns the baseline, fitting it first when a changed rating
+// invalidated it, nil when config.Baseline is not set.
+func (f *neighbourhoodModel[K]) fittedBaseline(config NeighbourhoodConfig, preferenceMatrix *KeyedMatrix[float64, Item, User]) (*baselineModel, error) {
+
+	if config.Baseline == nil {
+		return nil, nil
+	}
+
+	return f.baseline.loadOrFit(func() error {
+
+		baseline, err := fitBaseline(*config.Baseline, preferenceMatrix)
+
+		if err != nil {
+			return err
+		}
+
+		f.baseline.store(baseline)
+		return nil
+	})
+}
@@ -0,0 +1,126 @@
+package rec_engine
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+// changeRatings adds, changes and removes n random ratings through the
+// engine.
+func changeRatings[T Key](t *testing.T, rng *rand.Rand, recEngine *RecEngine[T], n int) {
+
+	t.Helper()
+
+	preferenceMatrix := recEngine.PreferenceMatrix
+
+	for range n {
+
+		item := preferenceMatrix.RowKeys[rng.Intn(preferenceMatrix.RowsN())]
+		user := preferenceMatrix.ColKeys[rng.Intn(preferenceMatrix.ColsN())]
+
+		var err error
+
+		if rating, _ := preferenceMatrix.LookupByKey(item, user); rating != 0 && rng.Intn(3) == 0 {
+			err = recEngine.RemoveRating(user, item)
+		} else {
+			err = recEngine.AddRating(user, item, float64(1+rng.Intn(5)))
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func assertSameCounts[K Key](t *testing.T, want *KeyedMatrix[int, K, K], got *KeyedMatrix[int, K, K]) {
+
+	t.Helper()
+
+	for i := range want.RowsN() {
+		for k := range want.ColsN() {
+
+			if want.Get(i, k) != got.Get(i, k) {
+				t.Fatalf("co-rated (%v, %v) = %d, want %d", want.RowKeys[i], want.ColKeys[k], got.Get(i, k), want.Get(i, k))
+			}
+		}
+	}
+}
+
+var incrementalOptions = map[string][]NeighbourhoodOption{
+	"cosine":       nil,
+	"model top-k":  {WithModelTopK(3)},
+	"significance": {WithSignificanceWeighting(4), WithModelTopK(2)},
+	"shrinkage":    {WithShrinkage(5)},
+}
+
+func TestUserBasedUpdateMatchesRebuild(t *testing.T) {
+
+	for name, options := range incrementalOptions {
+
+		t.Run(name, func(t *testing.T) {
+
+			rng := rand.New(rand.NewSource(1))
+			preferenceMatrix := randomPreferenceMatrix(t, rng, 15, 12, 0.4)
+
+			strategy := NewUserBasedStrategy(options...)
+			recEngine := NewRecEngine[User](preferenceMatrix, strategy)
+
+			if err := recEngine.Fit(); err != nil {
+				t.Fatal(err)
+			}
+
+			changeRatings(t, rng, recEngine, 60)
+
+			if strategy.SimilarityMatrix() == nil {
+				t.Fatal("the model was invalidated instead of updated")
+			}
+
+			rebuilt := NewUserBasedStrategy(options...)
+
+			if err := rebuilt.Fit(NewRecEngine[User](recEngine.PreferenceMatrix, rebuilt)); err != nil {
+				t.Fatal(err)
+			}
+
+			assertSameSimilarities(t, rebuilt.model.load().similarityMatrix, strategy.model.load().similarityMatrix)
+			assertSameSimilarities(t, rebuilt.SimilarityMatrix(), strategy.SimilarityMatrix())
+			assertSameCounts(t, rebuilt.model.load().coRatingMatrix, strategy.model.load().coRatingMatrix)
+		})
+	}
+}
+
+func TestItemBasedUpdateMatchesRebuild(t *testing.T) {
+
+	for name, options := range incrementalOptions {
+
+		t.Run(name, func(t *testing.T) {
+
+			rng := rand.New(rand.NewSource(2))
+			preferenceMatrix := randomPreferenceMatrix(t, rng, 12, 15, 0.4)
+
+			strategy := NewItemBasedStrategy(options...)
+			recEngine := NewRecEngine[Item](preferenceMatrix, strategy)
+
+			if err := recEngine.Fit(); err != nil {
+				t.Fatal(err)
+			}
+
+			changeRatings(t, rng, recEngine, 60)
+
+			if strategy.SimilarityMatrix() == nil {
+				t.Fatal("the model was invalidated instead of updated")
+			}
+
+			rebuilt := NewItemBasedStrategy(options...)
+
+			if err := rebuilt.Fit(NewRecEngine[Item](recEngine.PreferenceMatrix, rebuilt)); err != nil {
+				t.Fatal(err)
+			}
+
+			assertSameSimilarities(t, rebuilt.model.load().similarityMatrix, strategy.model.load().similarityMatrix)
+			assertSameSimilarities(t, rebuilt.SimilarityMatrix(), strategy.SimilarityMatrix())
+			assertSameCounts(t, rebuilt.model.load().coRatingMatrix, strategy.model.load().coRatingMatrix)
+		})
+	}
+}
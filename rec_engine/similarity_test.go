@@ -0,0 +1,247 @@
+package rec_engine
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+	"github.com/PetrDoroshev/RS/utils"
+)
+
+// randomPreferenceMatrix rates about density of the cells with 1..5.
+func randomPreferenceMatrix(t *testing.T, rng *rand.Rand, items_n int, users_n int, density float64) *KeyedMatrix[float64, Item, User] {
+
+	t.Helper()
+
+	items := make([]Item, items_n)
+	users := make([]User, users_n)
+
+	for i := range items {
+		items[i] = Item{Id: i + 1}
+	}
+
+	for i := range users {
+		users[i] = User{Id: i + 1}
+	}
+
+	coo := NewCoordinateList[float64](items_n, users_n)
+
+	for item_n := range items_n {
+		for user_n := range users_n {
+
+			if rng.Float64() < density {
+				coo.Append(item_n, user_n, float64(1+rng.Intn(5)))
+			}
+		}
+	}
+
+	preferenceMatrix, err := NewKeyedMatrix[float64](NewDualSparse(coo.ToCSR()), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return preferenceMatrix
+}
+
+func sameSimilarity(a float64, b float64) bool {
+	return math.IsNaN(a) == math.IsNaN(b) && (math.IsNaN(a) || math.Abs(a-b) <= 1e-9)
+}
+
+// assertSameSimilarities compares two similarity matrices, NaN being equal
+// to NaN.
+func assertSameSimilarities[K Key](t *testing.T, want *KeyedMatrix[float64, K, K], got *KeyedMatrix[float64, K, K]) {
+
+	t.Helper()
+
+	for i := range want.RowsN() {
+		for k := range want.ColsN() {
+
+			if a, b := want.Get(i, k), got.Get(i, k); !sameSimilarity(a, b) {
+				t.Fatalf("similarity (%v, %v) = %v, want %v", want.RowKeys[i], want.ColKeys[k], b, a)
+			}
+		}
+	}
+}
+
+func TestAdjustedCosineUsesCurrentMeans(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 12, 10, 0.6)
+
+	strategy := NewItemBasedStrategy(WithAdjustedCosine(), WithMinSimilarity(-1))
+	recEngine := NewRecEngine[Item](preferenceMatrix, strategy)
+
+	if err := recEngine.Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	before := strategy.SimilarityMatrix()
+
+	// shifts the mean of the first user
+	user := preferenceMatrix.ColKeys[0]
+
+	for _, item := range preferenceMatrix.RowKeys[:3] {
+		if err := recEngine.AddRating(user, item, 5); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := recEngine.Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	after := strategy.SimilarityMatrix()
+
+	// the same as a strategy built on the changed matrix
+	fresh, err := NewItemBasedStrategy(WithAdjustedCosine()).BuildSimilarityMatrix(preferenceMatrix.RowKeys, preferenceMatrix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameSimilarities(t, fresh, after)
+
+	means := meanRatings(preferenceMatrix.ColsN(), preferenceMatrix.GetSparseCol)
+	similarity := utils.SparseAdjustedCosine(means)
+	changed := false
+
+	for i := range after.RowsN() {
+		for k := range after.ColsN() {
+
+			if i == k {
+				continue
+			}
+
+			indices_1, values_1 := preferenceMatrix.GetSparseRow(i)
+			indices_2, values_2 := preferenceMatrix.GetSparseRow(k)
+			want, _ := similarity(indices_1, values_1, indices_2, values_2)
+
+			got := after.Get(i, k)
+
+			if math.IsNaN(want) != math.IsNaN(got) || (!math.IsNaN(want) && math.Abs(want-got) > 1e-9) {
+				t.Fatalf("similarity (%d, %d) = %v, want %v", i, k, got, want)
+			}
+
+			if math.Abs(before.Get(i, k)-got) > 1e-9 {
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		t.Fatal("the similarities didn't change with the means")
+	}
+}
+
+func TestSimilarityErrorsFailFit(t *testing.T) {
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(2)), 8, 6, 0.5)
+
+	// an undefined similarity is a NaN entry, not an error
+	if err := NewRecEngine[User](preferenceMatrix, NewUserBasedStrategy(WithSimilarity(utils.SparsePearsonCorrelation))).Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	failing := errors.New("failing similarity")
+	strategy := NewItemBasedStrategy(WithSimilarity(func([]int, []float64, []int, []float64) (float64, error) {
+		return 0, failing
+	}))
+
+	if err := NewRecEngine[Item](preferenceMatrix, strategy).Fit(); !errors.Is(err, failing) {
+		t.Fatalf("err = %v, want the error of the similarity", err)
+	}
+
+	if _, err := strategy.BuildSimilarityMatrix(preferenceMatrix.RowKeys, preferenceMatrix); !errors.Is(err, failing) {
+		t.Fatalf("err = %v, want the error of the similarity", err)
+	}
+}
+
+func TestSetSimilarityStrategy(t *testing.T) {
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(3)), 10, 8, 0.4)
+
+	strategy := NewItemBasedStrategy(WithSetSimilarity(utils.JaccardSimilarity), WithMinSimilarity(0))
+	recEngine := NewRecEngine[Item](preferenceMatrix, strategy)
+
+	if err := recEngine.Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	similarityMatrix := strategy.SimilarityMatrix()
+
+	for i := range preferenceMatrix.RowsN() {
+		for k := range preferenceMatrix.RowsN() {
+
+			if i == k {
+				continue
+			}
+
+			users_1, _ := preferenceMatrix.GetSparseRow(i)
+			users_2, _ := preferenceMatrix.GetSparseRow(k)
+			want, _ := utils.JaccardSimilarity(users_1, users_2)
+
+			if got := similarityMatrix.Get(i, k); !sameSimilarity(want, got) {
+				t.Fatalf("similarity (%d, %d) = %v, want the Jaccard index %v", i, k, got, want)
+			}
+		}
+	}
+}
+
+func TestSimilarityWeighting(t *testing.T) {
+
+	for _, test := range []struct {
+		config SimilarityConfig
+		want   float64
+	}{
+		{SimilarityConfig{}, 0.8},
+		{SimilarityConfig{SignificanceCutoff: 4}, 0.4},
+		{SimilarityConfig{SignificanceCutoff: 2}, 0.8},
+		{SimilarityConfig{Shrinkage: 2}, 0.4},
+		{SimilarityConfig{SignificanceCutoff: 4, Shrinkage: 2}, 0.2},
+	} {
+		if got := test.config.weight(0.8, 2); math.Abs(got-test.want) > 1e-12 {
+			t.Fatalf("%+v: weight of 0.8 for 2 co-rated = %v, want %v", test.config, got, test.want)
+		}
+	}
+
+	preferenceMatrix := randomPreferenceMatrix(t, rand.New(rand.NewSource(4)), 15, 10, 0.4)
+
+	raw, counts, err := NewUserBasedStrategy().BuildSimilarityMatrixWithCounts(preferenceMatrix.ColKeys, preferenceMatrix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weighted, _, err := NewUserBasedStrategy(WithSignificanceWeighting(5), WithShrinkage(3)).BuildSimilarityMatrixWithCounts(preferenceMatrix.ColKeys, preferenceMatrix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range raw.RowsN() {
+
+		items_1, _ := preferenceMatrix.GetSparseCol(i)
+
+		for k := range raw.ColsN() {
+
+			items_2, _ := preferenceMatrix.GetSparseCol(k)
+			n := utils.IntersectionSize(items_1, items_2)
+
+			if counts.Get(i, k) != n {
+				t.Fatalf("co-rated (%d, %d) = %d, want %d", i, k, counts.Get(i, k), n)
+			}
+
+			if i == k {
+				continue
+			}
+
+			want := raw.Get(i, k) * float64(min(n, 5)) / 5 * float64(n) / (float64(n) + 3)
+
+			if got := weighted.Get(i, k); !sameSimilarity(want, got) {
+				t.Fatalf("weighted similarity (%d, %d) = %v, want %v for %d co-rated", i, k, got, want, n)
+			}
+		}
+	}
+}
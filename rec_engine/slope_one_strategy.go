@@ -0,0 +1,172 @@
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+)
+
+// slopeOneModel holds for every pair of items, by their row index in the
+// preference matrix, the sum of the differences r_ui - r_uk over the users
+// who rated both and the amount of such users.
+type slopeOneModel struct {
+	diffs  [][]float64
+	counts [][]int
+}
+
+// deviation is the mean difference of the ratings of items i and k.
+func (m *slopeOneModel) deviation(i int, k int) float64 {
+	return m.diffs[i][k] / float64(m.counts[i][k])
+}
+
+// add adds (sign 1) or removes (sign -1) the rating of item i by a user whose
+// ratings are indices and values.
+func (m *slopeOneModel) add(i int, rating float64, indices []int, values []float64, sign int) {
+
+	for k, other := range indices {
+
+		if other == i || values[k] == 0 {
+			continue
+		}
+
+		diff := float64(sign) * (rating - values[k])
+
+		m.diffs[i][other] += diff
+		m.diffs[other][i] -= diff
+		m.counts[i][other] += sign
+		m.counts[other][i] += sign
+	}
+}
+
+// SlopeOneStrategy is the weighted Slope One predictor: every item rated by
+// the target user predicts its rating plus the mean deviation of the target
+// item from it, weighted by the amount of users who rated both.
+type SlopeOneStrategy struct {
+	lazyModel[slopeOneModel]
+}
+
+func NewSlopeOneStrategy() *SlopeOneStrategy {
+	return &SlopeOneStrategy{}
+}
+
+// Fit computes the deviations of all item pairs.
+func (s *SlopeOneStrategy) Fit(recEngine *RecEngine[Item]) error {
+
+	preferenceMatrix := recEngine.PreferenceMatrix
+	items_n := preferenceMatrix.RowsN()
+
+	model := &slopeOneModel{
+		diffs:  make([][]float64, items_n),
+		counts: make([][]int, items_n),
+	}
+
+	for i := range items_n {
+		model.diffs[i] = make([]float64, items_n)
+		model.counts[i] = make([]int, items_n)
+	}
+
+	for user_n := range preferenceMatrix.ColsN() {
+
+		indices, values := preferenceMatrix.GetSparseCol(user_n)
+
+		for k, item_n := range indices {
+
+			if values[k] == 0 {
+				continue
+			}
+
+			// every pair is visited from both of its items, so only the
+			// half with the smaller index is added each time
+			for j, other := range indices[:k] {
+
+				if values[j] == 0 {
+					continue
+				}
+
+				model.diffs[item_n][other] += values[k] - values[j]
+				model.diffs[other][item_n] += values[j] - values[k]
+				model.counts[item_n][other]++
+				model.counts[other][item_n]++
+			}
+		}
+	}
+
+	s.store(model)
+
+	return nil
+}
+
+// UpdateRating moves the old rating of the user out of the deviations and the
+// new one in.
+func (s *SlopeOneStrategy) UpdateRating(recEngine *RecEngine[Item], user User, item Item, old_rating float64, new_rating float64) {
+
+	item_n := recEngine.PreferenceMatrix.RowKeyToIndex[item]
+	indices, values := recEngine.PreferenceMatrix.GetSparseColByKey(user)
+
+	s.update(func(model *slopeOneModel) *slopeOneModel {
+
+		if old_rating != 0 {
+			model.add(item_n, old_rating, indices, values, -1)
+		}
+
+		if new_rating != 0 {
+			model.add(item_n, new_rating, indices, values, 1)
+		}
+
+		return model
+	})
+}
+
+// PredictRating returns NaN when the deviations could not be computed.
+func (s *SlopeOneStrategy) PredictRating(recEngine *RecEngine[Item], target_user User, target_item Item, explanation *Explanation) float64 {
+
+	model, err := s.loadOrFit(func() error { return s.Fit(recEngine) })
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("model could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	// UpdateRating changes the deviations in place
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target_n := recEngine.PreferenceMatrix.RowKeyToIndex[target_item]
+	indices, values := recEngine.PreferenceMatrix.GetSparseColByKey(target_user)
+
+	sum := 0.0
+	weight := 0
+
+	for k, item_n := range indices {
+
+		count := model.counts[target_n][item_n]
+
+		if item_n == target_n || values[k] == 0 || count == 0 {
+			continue
+		}
+
+		sum += (model.deviation(target_n, item_n) + values[k]) * float64(count)
+		weight += count
+
+		if explanation != nil {
+			explanation.Neighbours = append(explanation.Neighbours, Contribution{
+				User:      target_user,
+				Item:      recEngine.PreferenceMatrix.RowKeys[item_n],
+				Rating:    values[k],
+				Deviation: model.deviation(target_n, item_n),
+				Support:   count,
+			})
+		}
+	}
+
+	if weight == 0 {
+		return recEngine.explainedBaselineRating(target_user, target_item, explanation, "no items rated by the user were rated along with it")
+	}
+
+	if explanation != nil {
+		for k := range explanation.Neighbours {
+			explanation.Neighbours[k].Weight = float64(explanation.Neighbours[k].Support) / float64(weight)
+		}
+	}
+
+	return sum / float64(weight)
+}
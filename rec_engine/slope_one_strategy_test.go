@@ -0,0 +1,41 @@
+package rec_engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSlopeOneUpdateMatchesRebuild(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(3))
+	preferenceMatrix := randomPreferenceMatrix(t, rng, 12, 15, 0.4)
+
+	strategy := NewSlopeOneStrategy()
+	recEngine := NewRecEngine[Item](preferenceMatrix, strategy)
+
+	if err := recEngine.Fit(); err != nil {
+		t.Fatal(err)
+	}
+
+	changeRatings(t, rng, recEngine, 60)
+
+	rebuilt := NewSlopeOneStrategy()
+
+	if err := rebuilt.Fit(NewRecEngine[Item](recEngine.PreferenceMatrix, rebuilt)); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range rebuilt.model.diffs {
+		for k := range rebuilt.model.diffs[i] {
+
+			if strategy.model.counts[i][k] != rebuilt.model.counts[i][k] {
+				t.Fatalf("count (%d, %d) = %d, want %d", i, k, strategy.model.counts[i][k], rebuilt.model.counts[i][k])
+			}
+
+			if math.Abs(strategy.model.diffs[i][k]-rebuilt.model.diffs[i][k]) > 1e-9 {
+				t.Fatalf("diff (%d, %d) = %v, want %v", i, k, strategy.model.diffs[i][k], rebuilt.model.diffs[i][k])
+			}
+		}
+	}
+}
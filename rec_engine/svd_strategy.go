@@ -0,0 +1,201 @@
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+type Imputation int
+
+const (
+	// missing ratings are replaced with the mean rating of the user
+	UserMeanImputation Imputation = iota
+	// missing ratings are replaced with the mean rating of the item
+	ItemMeanImputation
+)
+
+type SVDConfig struct {
+	// amount of singular triplets kept
+	Rank int
+	// extra random vectors of the randomized SVD
+	Oversampling int
+	// passes refining the range of the randomized SVD
+	PowerIterations int
+	Imputation      Imputation
+	Seed            int64
+}
+
+type SVDOption func(*SVDConfig)
+
+func WithRank(rank int) SVDOption {
+	return func(c *SVDConfig) { c.Rank = rank }
+}
+
+func WithOversampling(oversampling int) SVDOption {
+	return func(c *SVDConfig) { c.Oversampling = oversampling }
+}
+
+func WithPowerIterations(iterations int) SVDOption {
+	return func(c *SVDConfig) { c.PowerIterations = iterations }
+}
+
+func WithImputation(imputation Imputation) SVDOption {
+	return func(c *SVDConfig) { c.Imputation = imputation }
+}
+
+func WithSVDSeed(seed int64) SVDOption {
+	return func(c *SVDConfig) { c.Seed = seed }
+}
+
+// SVDStrategy fills the missing ratings with the user or item means and
+// reconstructs them from the top Rank singular triplets. Filling with the
+// means and subtracting them leaves zeros in the missing cells, so only the
+// sparse matrix of deviations from the means is decomposed.
+type SVDStrategy[T Key] struct {
+	SVDConfig
+
+	lazyModel[factorModel]
+}
+
+// NewSVDStrategy defaults to rank 10 with user mean imputation, oversampling
+// 10 and 2 power iterations.
+func NewSVDStrategy[T Key](options ...SVDOption) *SVDStrategy[T] {
+
+	config := SVDConfig{
+		Rank:            10,
+		Oversampling:    10,
+		PowerIterations: 2,
+		Imputation:      UserMeanImputation,
+		Seed:            1,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return &SVDStrategy[T]{SVDConfig: config}
+}
+
+// Fit decomposes the preference matrix, the rank being lowered to the
+// smaller of its dimensions if needed.
+func (s *SVDStrategy[T]) Fit(recEngine *RecEngine[T]) error {
+
+	preferenceMatrix := recEngine.PreferenceMatrix
+	items_n, users_n := preferenceMatrix.RowsN(), preferenceMatrix.ColsN()
+
+	userSum, userCount := make([]float64, users_n), make([]int, users_n)
+	itemSum, itemCount := make([]float64, items_n), make([]int, items_n)
+	sum, count := 0.0, 0
+
+	for item_n := range items_n {
+
+		indices, values := preferenceMatrix.GetSparseRow(item_n)
+
+		for k, user_n := range indices {
+
+			if values[k] != 0 {
+
+				userSum[user_n] += values[k]
+				userCount[user_n]++
+				itemSum[item_n] += values[k]
+				itemCount[item_n]++
+				sum += values[k]
+				count++
+			}
+		}
+	}
+
+	model := &factorModel{
+		userBias:    make([]float64, users_n),
+		itemBias:    make([]float64, items_n),
+		userFactors: make([][]float64, users_n),
+		itemFactors: make([][]float64, items_n),
+	}
+
+	if count > 0 {
+		model.globalMean = sum / float64(count)
+	}
+
+	// the means are kept as biases on top of the global mean, objects
+	// without ratings getting the global mean
+	switch s.Imputation {
+	case UserMeanImputation:
+		for user_n := range users_n {
+			if userCount[user_n] > 0 {
+				model.userBias[user_n] = userSum[user_n]/float64(userCount[user_n]) - model.globalMean
+			}
+		}
+	case ItemMeanImputation:
+		for item_n := range items_n {
+			if itemCount[item_n] > 0 {
+				model.itemBias[item_n] = itemSum[item_n]/float64(itemCount[item_n]) - model.globalMean
+			}
+		}
+	default:
+		return fmt.Errorf("unknown imputation %d", s.Imputation)
+	}
+
+	deviations := NewCoordinateList[float64](items_n, users_n)
+
+	for item_n := range items_n {
+
+		indices, values := preferenceMatrix.GetSparseRow(item_n)
+
+		for k, user_n := range indices {
+
+			if values[k] != 0 {
+				deviations.Append(item_n, user_n, values[k]-model.predict(user_n, item_n))
+			}
+		}
+	}
+
+	rank := min(s.Rank, items_n, users_n)
+
+	if rank > 0 {
+
+		csr := deviations.ToCSR()
+		svd, err := TruncatedSVD(&csr, rank, s.Oversampling, s.PowerIterations, rand.New(rand.NewSource(s.Seed)))
+
+		if err != nil {
+			return err
+		}
+
+		for item_n := range items_n {
+
+			model.itemFactors[item_n] = make([]float64, rank)
+
+			for f := range rank {
+				model.itemFactors[item_n][f] = svd.U.Get(item_n, f) * svd.S[f]
+			}
+		}
+
+		for user_n := range users_n {
+			model.userFactors[user_n] = svd.V.GetRow(user_n)
+		}
+	}
+
+	s.store(model)
+
+	return nil
+}
+
+// PredictRating returns NaN when the decomposition failed.
+func (s *SVDStrategy[T]) PredictRating(recEngine *RecEngine[T], target_user User, target_item Item, explanation *Explanation) float64 {
+
+	model, err := s.loadOrFit(func() error { return s.Fit(recEngine) })
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("model could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	user_n := recEngine.PreferenceMatrix.ColKeyToIndex[target_user]
+	item_n := recEngine.PreferenceMatrix.RowKeyToIndex[target_item]
+
+	explanation.explainFactors(model, user_n, item_n)
+
+	return model.predict(user_n, item_n)
+}
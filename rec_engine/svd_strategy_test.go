@@ -0,0 +1,104 @@
+package rec_engine
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+// TestSVDStrategyFullRank checks that with the rank of the preference matrix
+// the deviations from the means are decomposed exactly: every rating is
+// reproduced and a missing one, a zero deviation, is predicted as the mean.
+func TestSVDStrategyFullRank(t *testing.T) {
+
+	items := []Item{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}}
+	users := []User{{Id: 1}, {Id: 2}, {Id: 3}}
+
+	// U3 hasn't rated P1
+	preferenceMatrix, err := NewKeyedMatrix[float64](NewMatrix([][]float64{
+		{5, 3, 0},
+		{4, 1, 2},
+		{1, 5, 4},
+		{2, 4, 3},
+	}), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		imputation Imputation
+		// mean of U3, or of P1
+		missing float64
+	}{
+		{UserMeanImputation, 3},
+		{ItemMeanImputation, 4},
+	} {
+
+		// the rank is lowered to 3
+		recEngine := NewRecEngine[User](preferenceMatrix, NewSVDStrategy[User](WithRank(10), WithImputation(test.imputation)))
+
+		for item_n, item := range items {
+			for user_n, user := range users {
+
+				want := preferenceMatrix.Get(item_n, user_n)
+
+				if want == 0 {
+					want = test.missing
+				}
+
+				got, err := recEngine.PredictRating(user, item)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if math.Abs(got-want) > 1e-9 {
+					t.Fatalf("imputation %d: (%v, %v) = %v, want %v", test.imputation, user, item, got, want)
+				}
+			}
+		}
+	}
+
+	recEngine := NewRecEngine[User](preferenceMatrix, NewSVDStrategy[User](WithImputation(Imputation(7))))
+
+	if rating, _ := recEngine.PredictRating(users[0], items[0]); !math.IsNaN(rating) {
+		t.Fatalf("rating %v with an unknown imputation, want NaN", rating)
+	}
+}
+
+func TestSVDStrategyLowRank(t *testing.T) {
+
+	items := []Item{{Id: 1}, {Id: 2}, {Id: 3}}
+	users := []User{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}}
+
+	// every user mean is 3 and the deviations are (1, -1, 0)ᵀ·(1, -1, 2, -2),
+	// so a single triplet reproduces all the ratings
+	preferenceMatrix, err := NewKeyedMatrix[float64](NewMatrix([][]float64{
+		{4, 2, 5, 1},
+		{2, 4, 1, 5},
+		{3, 3, 3, 3},
+	}), items, users)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recEngine := NewRecEngine[User](preferenceMatrix, NewSVDStrategy[User](WithRank(1)))
+
+	for item_n, item := range items {
+		for user_n, user := range users {
+
+			got, err := recEngine.PredictRating(user, item)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := preferenceMatrix.Get(item_n, user_n); math.Abs(got-want) > 1e-9 {
+				t.Fatalf("(%v, %v) = %v, want %v", user, item, got, want)
+			}
+		}
+	}
+}
@@ -1,95 +1,140 @@
-package rec_engine
-
-import (
-	"fmt"
-	"math"
-
-	. "github.com/PetrDoroshev/RS/matrix"
-	"github.com/PetrDoroshev/RS/utils"
-)
-
-type UserBasedStrategy struct{}
-
-func (s UserBasedStrategy) BuildSimilarityMatrix(objects_to_comp []User, preferenceMatrix *KeyedMatrix[float64, Item, User]) *KeyedMatrix[float64, User, User] {
-
-	similarityMatrix, _ := NewKeyedMatrix(*NewZeroMatrix[float64](len(objects_to_comp), len(objects_to_comp)),
-		objects_to_comp,
-		objects_to_comp,
-	)
-
-	for i, user_1 := range objects_to_comp {
-
-		for k := i + 1; k < len(objects_to_comp); k++ {
-
-			user_2 := objects_to_comp[k]
-
-			similarity, _ := utils.CosSimilarity(preferenceMatrix.GetColByKey(user_1),
-				preferenceMatrix.GetColByKey(user_2))
-
-			similarityMatrix.Set(i, k, similarity)
-			similarityMatrix.Set(k, i, similarity)
-		}
-	}
-
-	return similarityMatrix
-}
-
-func (s UserBasedStrategy) PredictRating(recEngine *RecEngine[User], target_user User, target_item Item, output bool) float64 {
-
-	var rating float64
-
-	target_user_index := recEngine.PreferenceMatrix.ColKeyToIndex[target_user]
-	target_item_index := recEngine.PreferenceMatrix.RowKeyToIndex[target_item]
-
-	users_to_comp := make([]User, 0, recEngine.PreferenceMatrix.ColsN())
-
-	for col_n := range recEngine.PreferenceMatrix.ColsN() {
-
-		if recEngine.PreferenceMatrix.Get(target_item_index, col_n) != 0 || col_n == target_user_index {
-
-			users_to_comp = append(users_to_comp, recEngine.PreferenceMatrix.ColKeys[col_n])
-		}
-	}
-
-	similarityMatrix := s.BuildSimilarityMatrix(users_to_comp, &recEngine.PreferenceMatrix)
-
-	if output {
-		fmt.Println("\nМатрица подобия:")
-		PrintSimilarityMatrix(similarityMatrix)
-	}
-
-	nearest_neighbours := []User{}
-	similarity_threshold := 0.65
-
-	for i, dist := range similarityMatrix.GetRowByKey(target_user) {
-
-		u := similarityMatrix.RowKeys[i]
-
-		if dist >= similarity_threshold && u != target_user {
-			nearest_neighbours = append(nearest_neighbours, u)
-		}
-
-	}
-
-	if output {
-		fmt.Println("\nБлижайшие соседи:")
-		fmt.Println(nearest_neighbours)
-	}
-
-	target_user_avg_rating := recEngine.AvgUserRating(target_user)
-	sum_of_dist := 0.0
-	sum_of_rating_diff := 0.0
-
-	for _, u := range nearest_neighbours {
-
-		user_avg_rating := recEngine.AvgUserRating(u)
-
-		sum_of_rating_diff += (recEngine.PreferenceMatrix.GetByKey(target_item, u) - user_avg_rating) * similarityMatrix.GetByKey(target_user, u)
-		sum_of_dist += math.Abs(similarityMatrix.GetByKey(target_user, u))
-
-	}
-
-	rating = target_user_avg_rating + (sum_of_rating_diff / sum_of_dist)
-
-	return rating
-}
+package rec_engine
+
+import (
+	"fmt"
+	"math"
+
+	. "github.com/PetrDoroshev/RS/matrix"
+)
+
+type UserBasedStrategy struct {
+	NeighbourhoodConfig
+	neighbourhoodModel[User]
+}
+
+// NewUserBasedStrategy defaults to neighbours with similarity >= 0.65 among
+// the users who rated the target item.
+func NewUserBasedStrategy(options ...NeighbourhoodOption) *UserBasedStrategy {
+	return &UserBasedStrategy{NeighbourhoodConfig: newNeighbourhoodConfig(0.65, options)}
+}
+
+func (s *UserBasedStrategy) BuildSimilarityMatrix(objects_to_comp []User, preferenceMatrix *KeyedMatrix[float64, Item, User]) (*KeyedMatrix[float64, User, User], error) {
+
+	similarityMatrix, _, err := s.BuildSimilarityMatrixWithCounts(objects_to_comp, preferenceMatrix)
+	return similarityMatrix, err
+}
+
+// BuildSimilarityMatrixWithCounts also returns the amount of entries rated by
+// both objects of every pair, the diagonal holding the amount rated by each.
+func (s *UserBasedStrategy) BuildSimilarityMatrixWithCounts(objects_to_comp []User, preferenceMatrix *KeyedMatrix[float64, Item, User]) (*KeyedMatrix[float64, User, User], *KeyedMatrix[int, User, User], error) {
+
+	return buildSimilarityMatrix(objects_to_comp,
+		preferenceMatrix.GetSparseColByKey,
+		func() []float64 { return meanRatings(preferenceMatrix.RowsN(), preferenceMatrix.GetSparseRow) },
+		s.SimilarityConfig,
+	)
+}
+
+// Fit computes the user-user similarity model used by PredictRating,
+// truncated to ModelTopK neighbours per user when it is set.
+func (s *UserBasedStrategy) Fit(recEngine *RecEngine[User]) error {
+
+	preferenceMatrix := recEngine.PreferenceMatrix
+	return s.fit(s.NeighbourhoodConfig, preferenceMatrix, preferenceMatrix.ColKeys, s.BuildSimilarityMatrixWithCounts, preferenceMatrix.GetSparseCol)
+}
+
+// UpdateRating applies a rating changed for the item to the similarities of
+// the user with every other user.
+func (s *UserBasedStrategy) UpdateRating(recEngine *RecEngine[User], user User, item Item, old_rating float64, new_rating float64) {
+
+	indices, values := recEngine.PreferenceMatrix.GetSparseRowByKey(item)
+	s.update(recEngine.PreferenceMatrix.ColKeyToIndex[user], indices, values, old_rating, new_rating)
+}
+
+// PredictRating adds to the mean rating of the target user, or the baseline,
+// the weighted deviations of the neighbours who rated the item. It returns NaN
+// when the similarities could not be computed.
+func (s *UserBasedStrategy) PredictRating(recEngine *RecEngine[User], target_user User, target_item Item, explanation *Explanation) float64 {
+
+	var rating float64
+
+	similarityMatrix, err := s.fittedMatrix(func() error { return s.Fit(recEngine) })
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("similarity model could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	indices, similarities := similarityMatrix.GetSparseRowByKey(target_user)
+	users := make([]User, len(indices))
+
+	for k, col_n := range indices {
+		users[k] = similarityMatrix.ColKeys[col_n]
+	}
+
+	nearest_neighbours := selectNeighbours(s.NeighbourhoodConfig,
+		users,
+		similarities,
+		target_user,
+		func(u User) bool {
+			rating, ok := recEngine.PreferenceMatrix.LookupByKey(target_item, u)
+			return ok && rating != 0
+		},
+	)
+
+	// the neighbours predict the deviation from the user's mean rating, or
+	// from the baseline when it is set
+	expected_rating := recEngine.AvgUserRating
+	baseline, err := s.fittedBaseline(s.NeighbourhoodConfig, recEngine.PreferenceMatrix)
+
+	if err != nil {
+		explanation.fallback(fmt.Sprintf("baseline could not be fitted: %v", err))
+		return math.NaN()
+	}
+
+	if baseline != nil {
+		expected_rating = func(u User) float64 {
+			return baseline.predictByKey(recEngine.PreferenceMatrix, u, target_item)
+		}
+	}
+
+	target_user_avg_rating := expected_rating(target_user)
+	sum_of_dist := 0.0
+	sum_of_rating_diff := 0.0
+
+	for _, u := range nearest_neighbours {
+
+		user_avg_rating := expected_rating(u.Key)
+		user_rating, _ := recEngine.PreferenceMatrix.LookupByKey(target_item, u.Key)
+
+		sum_of_rating_diff += (user_rating - user_avg_rating) * u.Similarity
+		sum_of_dist += math.Abs(u.Similarity)
+
+		if explanation != nil {
+			explanation.Neighbours = append(explanation.Neighbours, Contribution{
+				User:       u.Key,
+				Item:       target_item,
+				Similarity: u.Similarity,
+				Rating:     user_rating,
+				Deviation:  user_rating - user_avg_rating,
+			})
+		}
+	}
+
+	if !s.enoughNeighbours(len(nearest_neighbours)) || sum_of_dist == 0 {
+
+		reason := s.fallbackReason(len(nearest_neighbours), "rated the item")
+
+		if baseline != nil {
+			explanation.fallbackTo(reason, "baseline", target_user_avg_rating)
+			return target_user_avg_rating
+		}
+		return recEngine.explainedBaselineRating(target_user, target_item, explanation, reason)
+	}
+
+	explanation.weigh(target_user_avg_rating, sum_of_dist)
+
+	rating = target_user_avg_rating + (sum_of_rating_diff / sum_of_dist)
+
+	return rating
+}
@@ -1,43 +1,400 @@
-package utils
-
-import (
-	"errors"
-	"math"
-)
-
-func GetVectorLength(vector []float64) float64 {
-
-	sum := 0.0
-
-	for _, n := range vector {
-		sum += n * n
-	}
-
-	return math.Sqrt(sum)
-}
-
-func DotProduct(vector_1 []float64, vector_2 []float64) float64 {
-
-	vector_len := min(len(vector_1), len(vector_2))
-
-	dot := 0.0
-
-	for i := 0; i < vector_len; i++ {
-		dot += vector_1[i] * vector_2[i]
-	}
-
-	return dot
-
-}
-
-func CosSimilarity(v1 []float64, v2 []float64) (float64, error) {
-
-	v1_len := GetVectorLength(v1)
-	v2_len := GetVectorLength(v2)
-
-	if v1_len == 0 || v2_len == 0 {
-		return math.NaN(), errors.New("vector's length cannot be equals 0")
-	}
-
-	return DotProduct(v1, v2) / (v1_len * v2_len), nil
-}
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+func GetVectorLength(vector []float64) float64 {
+
+	sum := 0.0
+
+	for _, n := range vector {
+		sum += n * n
+	}
+
+	return math.Sqrt(sum)
+}
+
+func DotProduct(vector_1 []float64, vector_2 []float64) float64 {
+
+	vector_len := min(len(vector_1), len(vector_2))
+
+	dot := 0.0
+
+	for i := 0; i < vector_len; i++ {
+		dot += vector_1[i] * vector_2[i]
+	}
+
+	return dot
+
+}
+
+func CosSimilarity(v1 []float64, v2 []float64) (float64, error) {
+
+	v1_len := GetVectorLength(v1)
+	v2_len := GetVectorLength(v2)
+
+	if v1_len == 0 || v2_len == 0 {
+		return math.NaN(), undefined("vector's length cannot be equals 0")
+	}
+
+	return DotProduct(v1, v2) / (v1_len * v2_len), nil
+}
+
+// ErrUndefined is wrapped by the errors of similarities that aren't defined
+// for the given vectors, e.g. without co-rated entries, which return NaN.
+var ErrUndefined = errors.New("similarity is undefined")
+
+func undefined(reason string) error {
+	return fmt.Errorf("%w: %s", ErrUndefined, reason)
+}
+
+// Similarity compares two dense vectors, 0 meaning unrated.
+type Similarity func(v1 []float64, v2 []float64) (float64, error)
+
+// nonZero returns the indices and the values of the non-zero entries.
+func nonZero(vector []float64) ([]int, []float64) {
+
+	indices := []int{}
+	values := []float64{}
+
+	for i, value := range vector {
+		if value != 0 {
+			indices = append(indices, i)
+			values = append(values, value)
+		}
+	}
+
+	return indices, values
+}
+
+// dense adapts a sparse similarity to dense vectors.
+func dense(similarity SparseSimilarity) Similarity {
+
+	return func(v1 []float64, v2 []float64) (float64, error) {
+
+		indices_1, values_1 := nonZero(v1)
+		indices_2, values_2 := nonZero(v2)
+
+		return similarity(indices_1, values_1, indices_2, values_2)
+	}
+}
+
+// SparseDotProduct multiplies two sparse vectors given as ascending indices
+// and the values at them.
+func SparseDotProduct(indices_1 []int, values_1 []float64, indices_2 []int, values_2 []float64) float64 {
+
+	dot := 0.0
+
+	for i, k := 0, 0; i < len(indices_1) && k < len(indices_2); {
+
+		switch {
+		case indices_1[i] < indices_2[k]:
+			i++
+		case indices_1[i] > indices_2[k]:
+			k++
+		default:
+			dot += values_1[i] * values_2[k]
+			i++
+			k++
+		}
+	}
+
+	return dot
+}
+
+// SparseCosSimilarity is CosSimilarity of sparse vectors.
+func SparseCosSimilarity(indices_1 []int, values_1 []float64, indices_2 []int, values_2 []float64) (float64, error) {
+
+	v1_len := GetVectorLength(values_1)
+	v2_len := GetVectorLength(values_2)
+
+	if v1_len == 0 || v2_len == 0 {
+		return math.NaN(), undefined("vector's length cannot be equals 0")
+	}
+
+	return SparseDotProduct(indices_1, values_1, indices_2, values_2) / (v1_len * v2_len), nil
+}
+
+// SparseSimilarity compares two sparse vectors given as ascending indices
+// and the values at them, e.g. the rows returned by GetSparseRow. Entries
+// missing from a vector are unrated.
+type SparseSimilarity func(indices_1 []int, values_1 []float64, indices_2 []int, values_2 []float64) (float64, error)
+
+// coRated returns the indices and the pairs of values of the entries rated
+// in both vectors.
+func coRated(indices_1 []int, values_1 []float64, indices_2 []int, values_2 []float64) ([]int, []float64, []float64) {
+
+	indices := []int{}
+	x := []float64{}
+	y := []float64{}
+
+	for i, k := 0, 0; i < len(indices_1) && k < len(indices_2); {
+
+		switch {
+		case indices_1[i] < indices_2[k]:
+			i++
+		case indices_1[i] > indices_2[k]:
+			k++
+		default:
+			if values_1[i] != 0 && values_2[k] != 0 {
+				indices = append(indices, indices_1[i])
+				x = append(x, values_1[i])
+				y = append(y, values_2[k])
+			}
+			i++
+			k++
+		}
+	}
+
+	return indices, x, y
+}
+
+func mean(vector []float64) float64 {
+
+	sum := 0.0
+
+	for _, n := range vector {
+		sum += n
+	}
+
+	return sum / float64(len(vector))
+}
+
+func centeredCosine(x []float64, y []float64, x_mean float64, y_mean float64) (float64, error) {
+
+	dot, x_sq, y_sq := 0.0, 0.0, 0.0
+
+	for i := range x {
+
+		dx := x[i] - x_mean
+		dy := y[i] - y_mean
+
+		dot += dx * dy
+		x_sq += dx * dx
+		y_sq += dy * dy
+	}
+
+	if x_sq == 0 || y_sq == 0 {
+		return math.NaN(), undefined("vector's deviation cannot be equals 0")
+	}
+
+	return dot / math.Sqrt(x_sq*y_sq), nil
+}
+
+// PearsonCorrelation is computed over the co-rated entries only.
+func PearsonCorrelation(v1 []float64, v2 []float64) (float64, error) {
+	return dense(SparsePearsonCorrelation)(v1, v2)
+}
+
+// SparsePearsonCorrelation is PearsonCorrelation of sparse vectors.
+func SparsePearsonCorrelation(indices_1 []int, values_1 []float64, indices_2 []int, values_2 []float64) (float64, error) {
+
+	_, x, y := coRated(indices_1, values_1, indices_2, values_2)
+
+	if len(x) < 2 {
+		return math.NaN(), undefined("vectors must have at least 2 co-rated entries")
+	}
+
+	return centeredCosine(x, y, mean(x), mean(y))
+}
+
+// MeanCenteredCosine subtracts from each vector the mean of its own non-zero
+// entries and computes the cosine over all of them, unrated entries staying 0.
+func MeanCenteredCosine(v1 []float64, v2 []float64) (float64, error) {
+	return dense(SparseMeanCenteredCosine)(v1, v2)
+}
+
+// SparseMeanCenteredCosine is MeanCenteredCosine of sparse vectors.
+func SparseMeanCenteredCosine(indices_1 []int, values_1 []float64, indices_2 []int, values_2 []float64) (float64, error) {
+
+	center := func(values []float64) []float64 {
+
+		sum, n := 0.0, 0
+
+		for _, value := range values {
+			if value != 0 {
+				sum += value
+				n++
+			}
+		}
+
+		centered := make([]float64, len(values))
+
+		if n == 0 {
+			return centered
+		}
+
+		for i, value := range values {
+			if value != 0 {
+				centered[i] = value - sum/float64(n)
+			}
+		}
+
+		return centered
+	}
+
+	return SparseCosSimilarity(indices_1, center(values_1), indices_2, center(values_2))
+}
+
+// AdjustedCosine returns the similarity used by item-based CF: every
+// co-rated entry i is centered by means[i], the mean rating of the user the
+// entry belongs to.
+func AdjustedCosine(means []float64) Similarity {
+	return dense(SparseAdjustedCosine(means))
+}
+
+// SparseAdjustedCosine is AdjustedCosine of sparse vectors, means being
+// indexed by the indices of the entries. An entry without a mean is an error.
+func SparseAdjustedCosine(means []float64) SparseSimilarity {
+
+	return func(indices_1 []int, values_1 []float64, indices_2 []int, values_2 []float64) (float64, error) {
+
+		indices, x, y := coRated(indices_1, values_1, indices_2, values_2)
+
+		dot, x_sq, y_sq := 0.0, 0.0, 0.0
+
+		for k, i := range indices {
+
+			if i >= len(means) {
+				return math.NaN(), fmt.Errorf("no mean for entry %d, only %d means given", i, len(means))
+			}
+
+			dx := x[k] - means[i]
+			dy := y[k] - means[i]
+
+			dot += dx * dy
+			x_sq += dx * dx
+			y_sq += dy * dy
+		}
+
+		if x_sq == 0 || y_sq == 0 {
+			return math.NaN(), undefined("vector's deviation cannot be equals 0")
+		}
+
+		return dot / math.Sqrt(x_sq*y_sq), nil
+	}
+}
+
+// ranks assigns 1-based ranks, ties get the average of their positions.
+func ranks(vector []float64) []float64 {
+
+	order := make([]int, len(vector))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return vector[order[i]] < vector[order[j]]
+	})
+
+	result := make([]float64, len(vector))
+
+	for i := 0; i < len(order); {
+
+		k := i
+		for k < len(order) && vector[order[k]] == vector[order[i]] {
+			k++
+		}
+
+		rank := float64(i+k+1) / 2
+
+		for ; i < k; i++ {
+			result[order[i]] = rank
+		}
+	}
+
+	return result
+}
+
+// SpearmanCorrelation is the Pearson correlation of the ranks of the
+// co-rated entries.
+func SpearmanCorrelation(v1 []float64, v2 []float64) (float64, error) {
+	return dense(SparseSpearmanCorrelation)(v1, v2)
+}
+
+// SparseSpearmanCorrelation is SpearmanCorrelation of sparse vectors.
+func SparseSpearmanCorrelation(indices_1 []int, values_1 []float64, indices_2 []int, values_2 []float64) (float64, error) {
+
+	_, x, y := coRated(indices_1, values_1, indices_2, values_2)
+
+	if len(x) < 2 {
+		return math.NaN(), undefined("vectors must have at least 2 co-rated entries")
+	}
+
+	x_ranks, y_ranks := ranks(x), ranks(y)
+
+	return centeredCosine(x_ranks, y_ranks, mean(x_ranks), mean(y_ranks))
+}
+
+// SetSimilarity compares two sets given as ascending index lists, e.g. the
+// indices of the non-zero entries of two sparse rows.
+type SetSimilarity func(a []int, b []int) (float64, error)
+
+func IntersectionSize(a []int, b []int) int {
+
+	n := 0
+
+	for i, k := 0, 0; i < len(a) && k < len(b); {
+
+		switch {
+		case a[i] < b[k]:
+			i++
+		case a[i] > b[k]:
+			k++
+		default:
+			n++
+			i++
+			k++
+		}
+	}
+
+	return n
+}
+
+func JaccardSimilarity(a []int, b []int) (float64, error) {
+
+	if len(a) == 0 && len(b) == 0 {
+		return math.NaN(), undefined("sets cannot be both empty")
+	}
+
+	n := IntersectionSize(a, b)
+
+	return float64(n) / float64(len(a)+len(b)-n), nil
+}
+
+func DiceSimilarity(a []int, b []int) (float64, error) {
+
+	if len(a) == 0 && len(b) == 0 {
+		return math.NaN(), undefined("sets cannot be both empty")
+	}
+
+	return 2 * float64(IntersectionSize(a, b)) / float64(len(a)+len(b)), nil
+}
+
+// TanimotoSimilarity is the Tanimoto coefficient of the binary vectors
+// a·b / (|a|² + |b|² - a·b), which for sets equals the Jaccard index.
+func TanimotoSimilarity(a []int, b []int) (float64, error) {
+
+	if len(a) == 0 && len(b) == 0 {
+		return math.NaN(), undefined("sets cannot be both empty")
+	}
+
+	dot := float64(IntersectionSize(a, b))
+
+	return dot / (float64(len(a)) + float64(len(b)) - dot), nil
+}
+
+// OchiaiSimilarity is the cosine similarity of the binary vectors.
+func OchiaiSimilarity(a []int, b []int) (float64, error) {
+
+	if len(a) == 0 || len(b) == 0 {
+		return math.NaN(), undefined("set's size cannot be equals 0")
+	}
+
+	return float64(IntersectionSize(a, b)) / math.Sqrt(float64(len(a))*float64(len(b))), nil
+}
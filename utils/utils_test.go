@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDenseMatchesSparse(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+
+	means := make([]float64, 20)
+
+	for i := range means {
+		means[i] = 1 + 4*rng.Float64()
+	}
+
+	similarities := map[string]struct {
+		dense  Similarity
+		sparse SparseSimilarity
+	}{
+		"cosine":               {CosSimilarity, SparseCosSimilarity},
+		"pearson":              {PearsonCorrelation, SparsePearsonCorrelation},
+		"mean-centered cosine": {MeanCenteredCosine, SparseMeanCenteredCosine},
+		"spearman":             {SpearmanCorrelation, SparseSpearmanCorrelation},
+		"adjusted cosine":      {AdjustedCosine(means), SparseAdjustedCosine(means)},
+	}
+
+	for range 200 {
+
+		v1, v2 := make([]float64, 20), make([]float64, 20)
+
+		for i := range v1 {
+
+			if rng.Intn(2) == 0 {
+				v1[i] = float64(1 + rng.Intn(5))
+			}
+
+			if rng.Intn(2) == 0 {
+				v2[i] = float64(1 + rng.Intn(5))
+			}
+		}
+
+		indices_1, values_1 := nonZero(v1)
+		indices_2, values_2 := nonZero(v2)
+
+		for name, similarity := range similarities {
+
+			want, want_err := similarity.sparse(indices_1, values_1, indices_2, values_2)
+			got, err := similarity.dense(v1, v2)
+
+			if (err == nil) != (want_err == nil) || math.IsNaN(got) != math.IsNaN(want) || (!math.IsNaN(want) && math.Abs(got-want) > 1e-12) {
+				t.Fatalf("%s: dense %v (%v), sparse %v (%v)", name, got, err, want, want_err)
+			}
+		}
+	}
+
+	// the dense cosine compares whole vectors
+	if cos, err := CosSimilarity([]float64{1, 0, 1}, []float64{1, 1, 0}); err != nil || math.Abs(cos-0.5) > 1e-12 {
+		t.Fatalf("cosine = %v (%v), want 0.5", cos, err)
+	}
+}
+
+func TestAdjustedCosineWithoutMeans(t *testing.T) {
+
+	similarity := SparseAdjustedCosine([]float64{3, 3})
+
+	if _, err := similarity([]int{0, 1, 2}, []float64{1, 2, 3}, []int{0, 1, 2}, []float64{3, 2, 1}); err == nil || errors.Is(err, ErrUndefined) {
+		t.Fatalf("err = %v, want an error for the missing mean", err)
+	}
+
+	if _, err := similarity([]int{0}, []float64{3}, []int{1}, []float64{2}); !errors.Is(err, ErrUndefined) {
+		t.Fatalf("err = %v, want ErrUndefined without co-rated entries", err)
+	}
+}
+
+func TestSetSimilarities(t *testing.T) {
+
+	a, b := []int{1, 2, 3, 4}, []int{3, 4, 5}
+
+	for name, test := range map[string]struct {
+		similarity SetSimilarity
+		want       float64
+	}{
+		"jaccard":  {JaccardSimilarity, 2.0 / 5},
+		"dice":     {DiceSimilarity, 4.0 / 7},
+		"tanimoto": {TanimotoSimilarity, 2.0 / 5},
+		"ochiai":   {OchiaiSimilarity, 2 / math.Sqrt(12)},
+	} {
+
+		got, err := test.similarity(a, b)
+
+		if err != nil || math.Abs(got-test.want) > 1e-12 {
+			t.Fatalf("%s = %v (%v), want %v", name, got, err, test.want)
+		}
+
+		if same, _ := test.similarity(a, a); math.Abs(same-1) > 1e-12 {
+			t.Fatalf("%s of a set with itself = %v, want 1", name, same)
+		}
+
+		if disjoint, _ := test.similarity(a, []int{7, 8}); disjoint != 0 {
+			t.Fatalf("%s of disjoint sets = %v, want 0", name, disjoint)
+		}
+
+		if empty, err := test.similarity(nil, nil); !math.IsNaN(empty) || !errors.Is(err, ErrUndefined) {
+			t.Fatalf("%s of empty sets = %v (%v), want NaN and ErrUndefined", name, empty, err)
+		}
+	}
+
+	if n := IntersectionSize([]int{0, 2, 4, 6}, []int{1, 2, 3, 6, 9}); n != 2 {
+		t.Fatalf("intersection size %d, want 2", n)
+	}
+}